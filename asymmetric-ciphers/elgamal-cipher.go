@@ -0,0 +1,189 @@
+package ciphers
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+type ElGamal struct {
+	P *big.Int // safe prime modulus, p = 2q + 1
+	G *big.Int // generator of the order q subgroup
+	Y *big.Int // public key, y = g^x mod p
+	X *big.Int // private key
+}
+
+type ElGamalCiphertext struct {
+	C1 *big.Int // c1 = g^k mod p
+	C2 *big.Int // c2 = m * y^k mod p
+}
+
+// GenerateElGamalKeys generates an ElGamal keypair over a safe prime group
+// of the given bit size using the random source reader.
+func GenerateElGamalKeys(reader io.Reader, bits int) (elGamal ElGamal, err error) {
+	// p = 2q + 1, with p and q prime
+	var p, q *big.Int
+	for {
+		q, err = rand.Prime(reader, bits-1)
+		if err != nil {
+			return ElGamal{}, err
+		}
+		p = new(big.Int).Add(new(big.Int).Lsh(q, 1), big.NewInt(1))
+		if p.ProbablyPrime(20) {
+			break
+		}
+	}
+
+	// g = h^2 mod p generates the subgroup of quadratic residues of order q
+	var g *big.Int
+	for {
+		h, err := rand.Int(reader, p)
+		if err != nil {
+			return ElGamal{}, err
+		}
+		g = new(big.Int).Exp(h, big.NewInt(2), p)
+		if g.Cmp(big.NewInt(1)) > 0 {
+			break
+		}
+	}
+
+	// 1 <= x < q
+	x, err := rand.Int(reader, new(big.Int).Sub(q, big.NewInt(1)))
+	if err != nil {
+		return ElGamal{}, err
+	}
+	x.Add(x, big.NewInt(1))
+
+	// y = g^x mod p
+	y := new(big.Int).Exp(g, x, p)
+
+	e := ElGamal{
+		P: p,
+		G: g,
+		Y: y,
+		X: x,
+	}
+
+	return e, nil
+}
+
+// Encrypt encrypts m, which must be in the range [1, q], with a fresh
+// ephemeral key. m is first mapped into the subgroup of quadratic
+// residues, otherwise c2 would reveal whether m is a residue.
+func (e ElGamal) Encrypt(m *big.Int) (ElGamalCiphertext, error) {
+	if m == nil || m.Sign() <= 0 || m.Cmp(e.order()) > 0 {
+		return ElGamalCiphertext{}, errors.New("elgamal | message out of range")
+	}
+
+	// 1 <= k < p-1
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(e.P, big.NewInt(2)))
+	if err != nil {
+		return ElGamalCiphertext{}, err
+	}
+	k.Add(k, big.NewInt(1))
+
+	// c1 = g^k mod p
+	c1 := new(big.Int).Exp(e.G, k, e.P)
+
+	// c2 = encode(m) * y^k mod p
+	c2 := new(big.Int).Exp(e.Y, k, e.P)
+	c2.Mul(c2, e.encode(m)).Mod(c2, e.P)
+
+	return ElGamalCiphertext{C1: c1, C2: c2}, nil
+}
+
+// Decrypt decrypts c, whose parts must both be in the range [1, p).
+func (e ElGamal) Decrypt(c ElGamalCiphertext) (*big.Int, error) {
+	if !e.inGroup(c.C1) || !e.inGroup(c.C2) {
+		return nil, errors.New("elgamal | ciphertext out of range")
+	}
+
+	// s = c1^x mod p
+	s := new(big.Int).Exp(c.C1, e.X, e.P)
+
+	// m = c2 * s^-1 mod p
+	m := new(big.Int).ModInverse(s, e.P)
+	m.Mul(m, c.C2).Mod(m, e.P)
+	if !e.isResidue(m) {
+		return nil, errors.New("elgamal | invalid ciphertext")
+	}
+	return e.decode(m), nil
+}
+
+// inGroup reports whether v is in the range [1, p).
+func (e ElGamal) inGroup(v *big.Int) bool {
+	return v != nil && v.Sign() > 0 && v.Cmp(e.P) < 0
+}
+
+// order returns q = (p - 1) / 2, the order of the subgroup g generates.
+func (e ElGamal) order() *big.Int {
+	return new(big.Int).Rsh(e.P, 1)
+}
+
+// isResidue reports whether v is a quadratic residue mod p, v^q = 1.
+func (e ElGamal) isResidue(v *big.Int) bool {
+	return new(big.Int).Exp(v, e.order(), e.P).Cmp(big.NewInt(1)) == 0
+}
+
+// encode maps m in [1, q] to m or p - m, whichever is a quadratic residue.
+// -1 is not a residue for a safe prime p, so exactly one of them is.
+func (e ElGamal) encode(m *big.Int) *big.Int {
+	if e.isResidue(m) {
+		return new(big.Int).Set(m)
+	}
+	return new(big.Int).Sub(e.P, m)
+}
+
+// decode undoes encode by taking the one of v and p - v that is at most q.
+func (e ElGamal) decode(v *big.Int) *big.Int {
+	if v.Cmp(e.order()) > 0 {
+		return new(big.Int).Sub(e.P, v)
+	}
+	return v
+}
+
+// Multiply returns a ciphertext of the product of the plaintexts of a and b,
+// since E(m1) * E(m2) = E(m1 * m2 mod p). The encoding only flips signs, so
+// the product decrypts correctly while m1 * m2 stays at most q.
+func (e ElGamal) Multiply(a, b ElGamalCiphertext) ElGamalCiphertext {
+	c1 := new(big.Int).Mul(a.C1, b.C1)
+	c2 := new(big.Int).Mul(a.C2, b.C2)
+
+	return ElGamalCiphertext{
+		C1: c1.Mod(c1, e.P),
+		C2: c2.Mod(c2, e.P),
+	}
+}
+
+func (e ElGamal) EncryptMessage(s string) string {
+	m := new(big.Int).SetBytes([]byte(s))
+	c, err := e.Encrypt(m)
+	if err != nil {
+		return ""
+	}
+
+	// c1 and c2 are both padded to the byte length of p
+	size := (e.P.BitLen() + 7) / 8
+	enc := make([]byte, 2*size)
+	c.C1.FillBytes(enc[:size])
+	c.C2.FillBytes(enc[size:])
+	return string(enc)
+}
+
+func (e ElGamal) DecryptMessage(s string) string {
+	size := (e.P.BitLen() + 7) / 8
+	if len(s) != 2*size {
+		return ""
+	}
+
+	c := ElGamalCiphertext{
+		C1: new(big.Int).SetBytes([]byte(s[:size])),
+		C2: new(big.Int).SetBytes([]byte(s[size:])),
+	}
+	m, err := e.Decrypt(c)
+	if err != nil {
+		return ""
+	}
+	return string(m.Bytes())
+}
@@ -0,0 +1,136 @@
+package ciphers
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+type Paillier struct {
+	N       *big.Int // modulus, n = p * q
+	NSquare *big.Int // n^2
+	G       *big.Int // generator, g = n + 1
+	Lambda  *big.Int // private, lambda = lcm(p-1, q-1)
+	Mu      *big.Int // private, mu = lambda^-1 mod n
+}
+
+// GeneratePaillierKeys generates a Paillier keypair of the given bit size
+// using the random source reader.
+func GeneratePaillierKeys(reader io.Reader, bits int) (paillier Paillier, err error) {
+	var p, q *big.Int
+	for {
+		p, err = rand.Prime(reader, bits/2)
+		if err != nil {
+			return Paillier{}, err
+		}
+
+		q, err = rand.Prime(reader, bits/2)
+		if err != nil {
+			return Paillier{}, err
+		}
+
+		if p.Cmp(q) != 0 {
+			break
+		}
+	}
+
+	// n = p * q
+	n := new(big.Int).Mul(p, q)
+	nSquare := new(big.Int).Mul(n, n)
+
+	// g = n + 1
+	g := new(big.Int).Add(n, big.NewInt(1))
+
+	// lambda = lcm(p-1, q-1) = (p-1)(q-1) / gcd(p-1, q-1)
+	pMinus := new(big.Int).Sub(p, big.NewInt(1))
+	qMinus := new(big.Int).Sub(q, big.NewInt(1))
+	gcd := new(big.Int).GCD(nil, nil, pMinus, qMinus)
+	lambda := new(big.Int).Mul(pMinus, qMinus)
+	lambda.Div(lambda, gcd)
+
+	// with g = n + 1, L(g^lambda mod n^2) = lambda mod n, so mu = lambda^-1 mod n
+	mu := new(big.Int).ModInverse(lambda, n)
+	if mu == nil {
+		return Paillier{}, errors.New("paillier | lambda is not invertible mod n")
+	}
+
+	r := Paillier{
+		N:       n,
+		NSquare: nSquare,
+		G:       g,
+		Lambda:  lambda,
+		Mu:      mu,
+	}
+
+	return r, nil
+}
+
+// Encrypt encrypts m, which must be in the range [0, n), with a fresh
+// random r coprime to n.
+func (p Paillier) Encrypt(m *big.Int) (*big.Int, error) {
+	if m.Sign() < 0 || m.Cmp(p.N) >= 0 {
+		return nil, errors.New("paillier | message out of range")
+	}
+
+	var r *big.Int
+	for {
+		var err error
+		r, err = rand.Int(rand.Reader, p.N)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, p.N).Cmp(big.NewInt(1)) == 0 {
+			break
+		}
+	}
+
+	// c = g^m * r^n mod n^2
+	c := new(big.Int).Exp(p.G, m, p.NSquare)
+	c.Mul(c, new(big.Int).Exp(r, p.N, p.NSquare)).Mod(c, p.NSquare)
+
+	return c, nil
+}
+
+func (p Paillier) Decrypt(c *big.Int) *big.Int {
+	// m = L(c^lambda mod n^2) * mu mod n, where L(x) = (x - 1) / n
+	u := new(big.Int).Exp(c, p.Lambda, p.NSquare)
+	l := u.Sub(u, big.NewInt(1)).Div(u, p.N)
+	return l.Mul(l, p.Mu).Mod(l, p.N)
+}
+
+// Add returns a ciphertext of the sum of the plaintexts of a and b,
+// since E(m1) * E(m2) = E(m1 + m2 mod n).
+func (p Paillier) Add(a, b *big.Int) *big.Int {
+	c := new(big.Int).Mul(a, b)
+	return c.Mod(c, p.NSquare)
+}
+
+// AddPlain returns a ciphertext of the plaintext of c plus k,
+// since E(m) * g^k = E(m + k mod n).
+func (p Paillier) AddPlain(c, k *big.Int) *big.Int {
+	gk := new(big.Int).Exp(p.G, k, p.NSquare)
+	return gk.Mul(gk, c).Mod(gk, p.NSquare)
+}
+
+// MultiplyPlain returns a ciphertext of the plaintext of c times k,
+// since E(m)^k = E(m * k mod n).
+func (p Paillier) MultiplyPlain(c, k *big.Int) *big.Int {
+	return new(big.Int).Exp(c, k, p.NSquare)
+}
+
+// EncryptMessage returns an empty string for a message that does not fit
+// in the range [0, n).
+func (p Paillier) EncryptMessage(s string) string {
+	m := new(big.Int).SetBytes([]byte(s))
+	c, err := p.Encrypt(m)
+	if err != nil {
+		return ""
+	}
+	return string(c.Bytes())
+}
+
+func (p Paillier) DecryptMessage(s string) string {
+	c := new(big.Int).SetBytes([]byte(s))
+	return string(p.Decrypt(c).Bytes())
+}
@@ -0,0 +1,219 @@
+package ciphers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// Blind signatures follow RSABSSA-SHA384-PSS-Deterministic from RFC 9474:
+// the client blinds a PSS encoded message, the signer signs it without
+// seeing it, and the client unblinds the result into a regular RSASSA-PSS
+// signature that the signer cannot link back to the signing request.
+
+// BlindSaltLength is the PSS salt length, equal to the SHA-384 digest size.
+const BlindSaltLength = sha512.Size384
+
+var (
+	ErrBlindMessageTooLong = errors.New("rsa blind | message representative out of range")
+	ErrBlindVerification   = errors.New("rsa blind | signature verification failed")
+)
+
+// Blind encodes msg with EMSA-PSS and blinds it with a random r. The blinded
+// message goes to the signer, inv is kept by the client for Finalize.
+func (r RSA) Blind(reader io.Reader, msg []byte) (blindedMsg []byte, inv *big.Int, err error) {
+	em, err := emsaPSSEncode(reader, msg, r.N.BitLen()-1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := new(big.Int).SetBytes(em)
+	if new(big.Int).GCD(nil, nil, m, r.N).Cmp(big.NewInt(1)) != 0 {
+		return nil, nil, ErrBlindMessageTooLong
+	}
+
+	// r random with an inverse mod n
+	var blind *big.Int
+	for {
+		blind, err = rand.Int(reader, r.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		if blind.Sign() == 0 {
+			continue
+		}
+		inv = new(big.Int).ModInverse(blind, r.N)
+		if inv != nil {
+			break
+		}
+	}
+
+	// z = m * r^e mod n
+	z := new(big.Int).Exp(blind, r.E, r.N)
+	z.Mul(z, m).Mod(z, r.N)
+
+	return r.fixedBytes(z), inv, nil
+}
+
+// BlindSign signs a blinded message with the private exponent.
+func (r RSA) BlindSign(blindedMsg []byte) ([]byte, error) {
+	m := new(big.Int).SetBytes(blindedMsg)
+	if m.Cmp(r.N) >= 0 {
+		return nil, ErrBlindMessageTooLong
+	}
+
+	// s = m^d mod n
+	s := new(big.Int).Exp(m, r.D, r.N)
+
+	// guard against faulty signatures leaking the key
+	if new(big.Int).Exp(s, r.E, r.N).Cmp(m) != 0 {
+		return nil, errors.New("rsa blind | signing failed")
+	}
+
+	return r.fixedBytes(s), nil
+}
+
+// Finalize unblinds the signer's response into a signature on msg and
+// verifies it.
+func (r RSA) Finalize(msg, blindSig []byte, inv *big.Int) ([]byte, error) {
+	z := new(big.Int).SetBytes(blindSig)
+	if z.Cmp(r.N) >= 0 {
+		return nil, ErrBlindMessageTooLong
+	}
+
+	// s = z * r^-1 mod n
+	s := z.Mul(z, inv).Mod(z, r.N)
+	sig := r.fixedBytes(s)
+
+	if err := r.VerifyBlindSignature(msg, sig); err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// VerifyBlindSignature checks an RSASSA-PSS signature produced by Finalize.
+func (r RSA) VerifyBlindSignature(msg, sig []byte) error {
+	s := new(big.Int).SetBytes(sig)
+	if len(sig) != (r.N.BitLen()+7)/8 || s.Cmp(r.N) >= 0 {
+		return ErrBlindVerification
+	}
+
+	// m = s^e mod n
+	m := new(big.Int).Exp(s, r.E, r.N)
+
+	emBits := r.N.BitLen() - 1
+	em := make([]byte, (emBits+7)/8)
+	if m.BitLen() > 8*len(em) {
+		return ErrBlindVerification
+	}
+	m.FillBytes(em)
+
+	return emsaPSSVerify(msg, em, emBits)
+}
+
+func (r RSA) fixedBytes(x *big.Int) []byte {
+	out := make([]byte, (r.N.BitLen()+7)/8)
+	return x.FillBytes(out)
+}
+
+// emsaPSSEncode implements EMSA-PSS-ENCODE from RFC 8017 section 9.1.1
+// with SHA-384 and MGF1-SHA-384.
+func emsaPSSEncode(reader io.Reader, msg []byte, emBits int) ([]byte, error) {
+	hLen := sha512.Size384
+	emLen := (emBits + 7) / 8
+	if emLen < hLen+BlindSaltLength+2 {
+		return nil, errors.New("rsa blind | key too small for PSS encoding")
+	}
+
+	mHash := sha512.Sum384(msg)
+
+	salt := make([]byte, BlindSaltLength)
+	if _, err := io.ReadFull(reader, salt); err != nil {
+		return nil, err
+	}
+
+	// H = Hash(0x00 * 8 || mHash || salt)
+	h := pssHash(mHash[:], salt)
+
+	// DB = PS || 0x01 || salt
+	db := make([]byte, emLen-hLen-1)
+	db[len(db)-BlindSaltLength-1] = 0x01
+	copy(db[len(db)-BlindSaltLength:], salt)
+
+	mgf1XOR(db, sha512.New384(), h)
+	db[0] &= 0xff >> uint(8*emLen-emBits)
+
+	// EM = maskedDB || H || 0xbc
+	em := make([]byte, 0, emLen)
+	em = append(em, db...)
+	em = append(em, h...)
+	em = append(em, 0xbc)
+
+	return em, nil
+}
+
+// emsaPSSVerify implements EMSA-PSS-VERIFY from RFC 8017 section 9.1.2.
+func emsaPSSVerify(msg, em []byte, emBits int) error {
+	hLen := sha512.Size384
+	emLen := (emBits + 7) / 8
+	if len(em) != emLen || emLen < hLen+BlindSaltLength+2 || em[emLen-1] != 0xbc {
+		return ErrBlindVerification
+	}
+
+	db := append([]byte(nil), em[:emLen-hLen-1]...)
+	h := em[emLen-hLen-1 : emLen-1]
+
+	if db[0]&^(0xff>>uint(8*emLen-emBits)) != 0 {
+		return ErrBlindVerification
+	}
+
+	mgf1XOR(db, sha512.New384(), h)
+	db[0] &= 0xff >> uint(8*emLen-emBits)
+
+	psLen := emLen - hLen - BlindSaltLength - 2
+	if !bytes.Equal(db[:psLen], make([]byte, psLen)) || db[psLen] != 0x01 {
+		return ErrBlindVerification
+	}
+	salt := db[len(db)-BlindSaltLength:]
+
+	mHash := sha512.Sum384(msg)
+	if subtle.ConstantTimeCompare(pssHash(mHash[:], salt), h) != 1 {
+		return ErrBlindVerification
+	}
+
+	return nil
+}
+
+func pssHash(mHash, salt []byte) []byte {
+	hash := sha512.New384()
+	hash.Write(make([]byte, 8))
+	hash.Write(mHash)
+	hash.Write(salt)
+	return hash.Sum(nil)
+}
+
+// mgf1XOR xors out with the MGF1 mask generated from seed.
+func mgf1XOR(out []byte, hash hash.Hash, seed []byte) {
+	var counter [4]byte
+	done := 0
+	for i := uint32(0); done < len(out); i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+
+		hash.Reset()
+		hash.Write(seed)
+		hash.Write(counter[:])
+		digest := hash.Sum(nil)
+
+		for j := 0; j < len(digest) && done < len(out); j++ {
+			out[done] ^= digest[j]
+			done++
+		}
+	}
+}
@@ -10,12 +10,28 @@ type RSA struct {
 	N *big.Int // modulus
 	E *big.Int // public exponent
 	D *big.Int // private exponent
+	P *big.Int // first prime factor, nil for public keys
+	Q *big.Int // second prime factor, nil for public keys
+}
+
+type RSAKeyOptions struct {
+	// AllowWeak returns the key even when validation reports critical findings.
+	AllowWeak bool
 }
 
 // GenerateKey generates an RSA keypair of the given bit size using the
 // random source rand (for example, crypto/rand.Reader).
+// Keys with critical findings from ValidateRSAKey are refused with a *WeakKeyError.
 func GenerateRSAKeys(reader io.Reader, bits int) (rsa RSA, err error) {
-	p, err := rand.Prime(reader, bits/2)
+	return GenerateRSAKeysWithOptions(reader, bits, RSAKeyOptions{})
+}
+
+// GenerateRSAKeysWithOptions is GenerateRSAKeys with the validation behaviour
+// controlled by opts.
+func GenerateRSAKeysWithOptions(reader io.Reader, bits int, opts RSAKeyOptions) (rsa RSA, err error) {
+	// the prime sizes add up to bits, rand.Prime sets the top two bits of
+	// each so n has exactly bits bits even when bits is odd
+	p, err := rand.Prime(reader, (bits+1)/2)
 	if err != nil {
 		return RSA{}, err
 	}
@@ -32,13 +48,13 @@ func GenerateRSAKeys(reader io.Reader, bits int) (rsa RSA, err error) {
 	phi := new(big.Int).Mul(new(big.Int).Sub(p, big.NewInt(1)), new(big.Int).Sub(q, big.NewInt(1)))
 
 	// 1 < e < phi, gcd(e,phi) = 1
-	e, err := rand.Int(rand.Reader, phi)
+	e, err := rand.Int(reader, phi)
 	if err != nil {
 		return RSA{}, err
 	}
 	gcd := big.Int{}
-	for gcd.GCD(nil, nil, e, phi).Cmp(big.NewInt(1)) != 0 {
-		e, err = rand.Int(rand.Reader, phi)
+	for e.Cmp(big.NewInt(1)) <= 0 || gcd.GCD(nil, nil, e, phi).Cmp(big.NewInt(1)) != 0 {
+		e, err = rand.Int(reader, phi)
 		if err != nil {
 			return RSA{}, err
 		}
@@ -51,9 +67,19 @@ func GenerateRSAKeys(reader io.Reader, bits int) (rsa RSA, err error) {
 		N: n,
 		E: e,
 		D: d,
+		P: p,
+		Q: q,
+	}
+
+	if opts.AllowWeak {
+		return r, nil
+	}
+
+	if findings := ValidateRSAKey(r, bits); HasCriticalFindings(findings) {
+		return RSA{}, &WeakKeyError{Findings: findings}
 	}
 
-	return r, err
+	return r, nil
 }
 
 func (r RSA) EncryptMessage(s string) string {
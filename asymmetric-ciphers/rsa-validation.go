@@ -0,0 +1,130 @@
+package ciphers
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MinRSAKeyBits is the smallest modulus accepted without a critical finding.
+const MinRSAKeyBits = 2048
+
+// StandardRSAExponent is the public exponent used by virtually every RSA
+// implementation, F4 = 2^16 + 1.
+const StandardRSAExponent = 65537
+
+type KeyFindingCode string
+
+const (
+	FindingModulusTooSmall       KeyFindingCode = "modulus-too-small"
+	FindingModulusBitLength      KeyFindingCode = "modulus-bit-length"
+	FindingEqualPrimes           KeyFindingCode = "equal-primes"
+	FindingClosePrimes           KeyFindingCode = "close-primes"
+	FindingSmallPrivateExponent  KeyFindingCode = "small-private-exponent"
+	FindingNonStandardExponent   KeyFindingCode = "non-standard-exponent"
+	FindingInvalidPublicExponent KeyFindingCode = "invalid-public-exponent"
+)
+
+type KeyFindingSeverity int
+
+const (
+	SeverityWarning KeyFindingSeverity = iota
+	SeverityCritical
+)
+
+func (s KeyFindingSeverity) String() string {
+	if s == SeverityCritical {
+		return "critical"
+	}
+	return "warning"
+}
+
+type KeyFinding struct {
+	Code     KeyFindingCode
+	Severity KeyFindingSeverity
+	Message  string
+}
+
+// WeakKeyError is returned by key generation when a key has critical findings.
+type WeakKeyError struct {
+	Findings []KeyFinding
+}
+
+func (e *WeakKeyError) Error() string {
+	var codes []string
+	for _, f := range e.Findings {
+		if f.Severity == SeverityCritical {
+			codes = append(codes, string(f.Code))
+		}
+	}
+	return "rsa | weak key: " + strings.Join(codes, ", ")
+}
+
+// ValidateRSAKey checks key for known weaknesses. bits is the requested
+// modulus size, or 0 to skip the bit length check. The prime checks run only
+// when P and Q are known, and the private exponent check only when D is known.
+func ValidateRSAKey(key RSA, bits int) []KeyFinding {
+	var findings []KeyFinding
+	add := func(code KeyFindingCode, severity KeyFindingSeverity, format string, args ...interface{}) {
+		findings = append(findings, KeyFinding{Code: code, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	nBits := key.N.BitLen()
+
+	if nBits < MinRSAKeyBits {
+		add(FindingModulusTooSmall, SeverityCritical, "modulus is %d bits, minimum is %d", nBits, MinRSAKeyBits)
+	}
+
+	if bits > 0 && nBits != bits {
+		add(FindingModulusBitLength, SeverityCritical, "modulus is %d bits, requested %d", nBits, bits)
+	}
+
+	// e must be odd and greater than 2, anything but F4 is unusual
+	if key.E.Cmp(big.NewInt(3)) < 0 || key.E.Bit(0) == 0 {
+		add(FindingInvalidPublicExponent, SeverityCritical, "public exponent %s is invalid", key.E)
+	} else if key.E.Cmp(big.NewInt(StandardRSAExponent)) != 0 {
+		add(FindingNonStandardExponent, SeverityWarning, "public exponent %s is not %d", key.E, StandardRSAExponent)
+	}
+
+	if key.P != nil && key.Q != nil {
+		diff := new(big.Int).Sub(key.P, key.Q)
+		diff.Abs(diff)
+
+		// FIPS 186-4 B.3.1 requires |p - q| > 2^(nlen/2 - 100)
+		minDiff := new(big.Int).Lsh(big.NewInt(1), uint(maxInt(nBits/2-100, 0)))
+
+		if diff.Sign() == 0 {
+			add(FindingEqualPrimes, SeverityCritical, "p and q are equal")
+		} else if diff.Cmp(minDiff) <= 0 {
+			add(FindingClosePrimes, SeverityCritical, "|p - q| is %d bits, Fermat factoring applies", diff.BitLen())
+		}
+	}
+
+	if key.D != nil {
+		// Wiener's attack recovers d < n^(1/4) / 3
+		bound := new(big.Int).Sqrt(new(big.Int).Sqrt(key.N))
+		bound.Div(bound, big.NewInt(3))
+
+		if key.D.Cmp(bound) < 0 {
+			add(FindingSmallPrivateExponent, SeverityCritical, "private exponent is %d bits, below the Wiener bound", key.D.BitLen())
+		}
+	}
+
+	return findings
+}
+
+func HasCriticalFindings(findings []KeyFinding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,149 @@
+package rsaattacks
+
+import (
+	"errors"
+	"math/big"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+)
+
+// Root returns floor(x^(1/n)) and whether the root is exact, for x >= 0 and
+// n >= 1.
+func Root(x *big.Int, n int) (*big.Int, bool, error) {
+	if n < 1 {
+		return nil, false, errors.New("rsaattacks Root | exponent must be positive")
+	}
+	if x.Sign() < 0 {
+		return nil, false, errors.New("rsaattacks Root | x must not be negative")
+	}
+	if x.Sign() == 0 || n == 1 {
+		return new(big.Int).Set(x), true, nil
+	}
+	// 2^n > x, so the root is 1
+	if n >= x.BitLen() {
+		return big.NewInt(1), x.Cmp(one) == 0, nil
+	}
+
+	bn := big.NewInt(int64(n))
+	bnMinus := big.NewInt(int64(n - 1))
+
+	// Newton's method starting from a power of two above the root:
+	// r = ((n-1)*r + x / r^(n-1)) / n
+	r := new(big.Int).Lsh(one, uint(x.BitLen()/n+1))
+	for {
+		next := new(big.Int).Mul(bnMinus, r)
+		next.Add(next, new(big.Int).Div(x, new(big.Int).Exp(r, bnMinus, nil)))
+		next.Div(next, bn)
+		if next.Cmp(r) >= 0 {
+			break
+		}
+		r = next
+	}
+
+	return r, new(big.Int).Exp(r, bn, nil).Cmp(x) == 0, nil
+}
+
+// CRT returns the unique x modulo the product of moduli with x = rs[i] mod moduli[i].
+func CRT(rs, moduli []*big.Int) (*big.Int, error) {
+	if len(rs) != len(moduli) {
+		return nil, errors.New("rsaattacks CRT | need one remainder per modulus")
+	}
+
+	product := big.NewInt(1)
+	for _, m := range moduli {
+		if m.Sign() <= 0 {
+			return nil, errors.New("rsaattacks CRT | moduli must be positive")
+		}
+		product.Mul(product, m)
+	}
+
+	x := new(big.Int)
+	for i, m := range moduli {
+		mi := new(big.Int).Div(product, m)
+		inv := new(big.Int).ModInverse(mi, m)
+		if inv == nil {
+			return nil, errors.New("rsaattacks CRT | moduli are not pairwise coprime")
+		}
+		term := new(big.Int).Mul(rs[i], mi)
+		x.Add(x, term.Mul(term, inv))
+	}
+
+	return x.Mod(x, product), nil
+}
+
+// convergents returns the convergents [k, d] of the continued fraction of a/b.
+func convergents(a, b *big.Int) [][2]*big.Int {
+	var result [][2]*big.Int
+
+	num, den := new(big.Int).Set(a), new(big.Int).Set(b)
+	hPrev, h := big.NewInt(0), big.NewInt(1)
+	kPrev, k := big.NewInt(1), big.NewInt(0)
+
+	for den.Sign() != 0 {
+		q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+		num, den = den, r
+
+		hPrev, h = h, new(big.Int).Add(new(big.Int).Mul(q, h), hPrev)
+		kPrev, k = k, new(big.Int).Add(new(big.Int).Mul(q, k), kPrev)
+
+		result = append(result, [2]*big.Int{h, k})
+	}
+
+	return result
+}
+
+// solveFactors returns p and q with p + q = s and p * q = n, if they are integers.
+func solveFactors(n, s *big.Int) (*big.Int, *big.Int, bool) {
+	// discriminant = s^2 - 4n
+	disc := new(big.Int).Sub(new(big.Int).Mul(s, s), new(big.Int).Lsh(n, 2))
+	if disc.Sign() < 0 {
+		return nil, nil, false
+	}
+
+	root := new(big.Int).Sqrt(disc)
+	if new(big.Int).Mul(root, root).Cmp(disc) != 0 {
+		return nil, nil, false
+	}
+
+	p := new(big.Int).Add(s, root)
+	q := new(big.Int).Sub(s, root)
+	if p.Bit(0) != 0 || q.Bit(0) != 0 {
+		return nil, nil, false
+	}
+	p.Rsh(p, 1)
+	q.Rsh(q, 1)
+
+	if new(big.Int).Mul(p, q).Cmp(n) != 0 {
+		return nil, nil, false
+	}
+	return p, q, true
+}
+
+// fromFactors rebuilds the full private key of pub from its prime factors.
+func fromFactors(pub ciphers.RSA, p, q *big.Int) (ciphers.RSA, error) {
+	if new(big.Int).Mul(p, q).Cmp(pub.N) != 0 {
+		return ciphers.RSA{}, errors.New("rsaattacks | factors do not match modulus")
+	}
+
+	// d = e^-1 mod phi
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	d := new(big.Int).ModInverse(pub.E, phi)
+	if d == nil {
+		return ciphers.RSA{}, errors.New("rsaattacks | exponent is not invertible")
+	}
+
+	return ciphers.RSA{N: pub.N, E: pub.E, D: d, P: p, Q: q}, nil
+}
+
+// expSigned returns x^y mod n, inverting x first when y is negative.
+func expSigned(x, y, n *big.Int) (*big.Int, error) {
+	if y.Sign() >= 0 {
+		return new(big.Int).Exp(x, y, n), nil
+	}
+
+	inv := new(big.Int).ModInverse(x, n)
+	if inv == nil {
+		return nil, errors.New("rsaattacks | ciphertext is not invertible")
+	}
+	return inv.Exp(inv, new(big.Int).Neg(y), n), nil
+}
@@ -0,0 +1,171 @@
+package rsaattacks
+
+import (
+	"errors"
+	"math/big"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+)
+
+var one = big.NewInt(1)
+
+// SmallExponent recovers m from c = m^e mod n when m^e < n, in which case
+// the modular reduction never happened and m is just the integer e-th root of c.
+func SmallExponent(pub ciphers.RSA, c *big.Int) (*big.Int, error) {
+	// m^e < n needs e below the bit length of n for any m > 1
+	if pub.E.Sign() <= 0 || pub.E.Cmp(big.NewInt(int64(pub.N.BitLen()))) > 0 {
+		return nil, errors.New("rsaattacks SmallExponent | exponent out of range")
+	}
+
+	m, exact, err := Root(c, int(pub.E.Int64()))
+	if err != nil {
+		return nil, err
+	}
+	if !exact {
+		return nil, errors.New("rsaattacks SmallExponent | ciphertext is not a perfect power")
+	}
+
+	return m, nil
+}
+
+// CommonModulus recovers m when the same message was encrypted under the same
+// modulus with two coprime exponents: with a*e1 + b*e2 = 1, m = c1^a * c2^b mod n.
+func CommonModulus(pub1, pub2 ciphers.RSA, c1, c2 *big.Int) (*big.Int, error) {
+	if pub1.N.Cmp(pub2.N) != 0 {
+		return nil, errors.New("rsaattacks CommonModulus | moduli differ")
+	}
+	n := pub1.N
+
+	a, b := new(big.Int), new(big.Int)
+	gcd := new(big.Int).GCD(a, b, pub1.E, pub2.E)
+	if gcd.Cmp(one) != 0 {
+		return nil, errors.New("rsaattacks CommonModulus | exponents are not coprime")
+	}
+
+	m1, err := expSigned(c1, a, n)
+	if err != nil {
+		return nil, err
+	}
+	m2, err := expSigned(c2, b, n)
+	if err != nil {
+		return nil, err
+	}
+
+	m := m1.Mul(m1, m2)
+	return m.Mod(m, n), nil
+}
+
+// Wiener recovers the private key when d < n^(1/4) / 3 by walking the
+// convergents k/d of the continued fraction of e/n.
+func Wiener(pub ciphers.RSA) (ciphers.RSA, error) {
+	for _, c := range convergents(pub.E, pub.N) {
+		k, d := c[0], c[1]
+		if k.Sign() == 0 {
+			continue
+		}
+
+		// phi = (e*d - 1) / k must be an integer
+		phi, rem := new(big.Int).QuoRem(new(big.Int).Sub(new(big.Int).Mul(pub.E, d), one), k, new(big.Int))
+		if rem.Sign() != 0 {
+			continue
+		}
+
+		// p and q are the roots of x^2 - (n - phi + 1)x + n
+		s := new(big.Int).Add(new(big.Int).Sub(pub.N, phi), one)
+		p, q, ok := solveFactors(pub.N, s)
+		if !ok {
+			continue
+		}
+
+		return fromFactors(pub, p, q)
+	}
+
+	return ciphers.RSA{}, errors.New("rsaattacks Wiener | private exponent is not small enough")
+}
+
+// Fermat factors n when |p - q| is small, by searching a such that a^2 - n is
+// a perfect square b^2; then n = (a - b)(a + b).
+func Fermat(pub ciphers.RSA, maxIterations int) (ciphers.RSA, error) {
+	n := pub.N
+
+	// a = ceil(sqrt(n))
+	a := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(a, a).Cmp(n) < 0 {
+		a.Add(a, one)
+	}
+
+	for i := 0; i < maxIterations; i++ {
+		b2 := new(big.Int).Sub(new(big.Int).Mul(a, a), n)
+		b := new(big.Int).Sqrt(b2)
+		if new(big.Int).Mul(b, b).Cmp(b2) == 0 {
+			p := new(big.Int).Sub(a, b)
+			q := new(big.Int).Add(a, b)
+			return fromFactors(pub, p, q)
+		}
+		a.Add(a, one)
+	}
+
+	return ciphers.RSA{}, errors.New("rsaattacks Fermat | primes are not close enough")
+}
+
+// PollardPMinus1 factors n when p - 1 is bound-smooth for one of its prime
+// factors p: a = 2^(bound!) mod n, then gcd(a - 1, n) reveals p.
+func PollardPMinus1(pub ciphers.RSA, bound int) (ciphers.RSA, error) {
+	n := pub.N
+	a := big.NewInt(2)
+
+	for j := int64(2); j <= int64(bound); j++ {
+		a.Exp(a, big.NewInt(j), n)
+
+		g := new(big.Int).GCD(nil, nil, new(big.Int).Sub(a, one), n)
+		if g.Cmp(one) > 0 && g.Cmp(n) < 0 {
+			q := new(big.Int).Div(n, g)
+			return fromFactors(pub, g, q)
+		}
+		if g.Cmp(n) == 0 {
+			break
+		}
+	}
+
+	return ciphers.RSA{}, errors.New("rsaattacks PollardPMinus1 | no factor found within bound")
+}
+
+// Hastad recovers m when the same message was encrypted with the same small
+// exponent e under at least e pairwise coprime moduli: the CRT gives m^e over
+// the product of the moduli, and m is its integer e-th root.
+func Hastad(pubs []ciphers.RSA, cs []*big.Int) (*big.Int, error) {
+	if len(pubs) == 0 || len(pubs) != len(cs) {
+		return nil, errors.New("rsaattacks Hastad | need one ciphertext per public key")
+	}
+
+	e := pubs[0].E
+	if e.Sign() <= 0 {
+		return nil, errors.New("rsaattacks Hastad | exponent must be positive")
+	}
+	if !e.IsInt64() || int64(len(pubs)) < e.Int64() {
+		return nil, errors.New("rsaattacks Hastad | need at least e ciphertexts")
+	}
+
+	moduli := make([]*big.Int, len(pubs))
+	for i, pub := range pubs {
+		if pub.E.Cmp(e) != 0 {
+			return nil, errors.New("rsaattacks Hastad | exponents differ")
+		}
+		moduli[i] = pub.N
+	}
+
+	me, err := CRT(cs, moduli)
+	if err != nil {
+		return nil, err
+	}
+
+	m, exact, err := Root(me, int(e.Int64()))
+	if err != nil {
+		return nil, err
+	}
+	if !exact {
+		return nil, errors.New("rsaattacks Hastad | combined ciphertext is not a perfect power")
+	}
+
+	return m, nil
+}
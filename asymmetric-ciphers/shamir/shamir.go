@@ -0,0 +1,248 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+)
+
+// Prime is the Mersenne prime 2^521 - 1, the field all shares live in.
+var Prime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 521), big.NewInt(1))
+
+// ChunkSize is the number of secret bytes shared by one polynomial,
+// 64 bytes always fit below Prime.
+const ChunkSize = 64
+
+const encodingPrefix = "shamir-v1"
+
+type Share struct {
+	Index     int        // x coordinate, 1..n
+	Threshold int        // k, shares needed to reconstruct
+	Length    int        // secret length in bytes
+	Values    []*big.Int // y coordinate, one per secret chunk
+}
+
+// Split splits secret into n shares such that any k of them reconstruct it
+// and any k-1 of them reveal nothing about it.
+func Split(secret []byte, n, k int) ([]Share, error) {
+	if k < 2 || n < k {
+		return nil, errors.New("shamir Split | need 2 <= k <= n")
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{Index: i + 1, Threshold: k, Length: len(secret)}
+	}
+
+	for start := 0; ; start += ChunkSize {
+		end := start + ChunkSize
+		if end > len(secret) {
+			end = len(secret)
+		}
+
+		// f(x) = secret + a1*x + ... + a(k-1)*x^(k-1) mod Prime
+		coefficients := make([]*big.Int, k)
+		coefficients[0] = new(big.Int).SetBytes(secret[start:end])
+		for i := 1; i < k; i++ {
+			a, err := rand.Int(rand.Reader, Prime)
+			if err != nil {
+				return nil, err
+			}
+			coefficients[i] = a
+		}
+
+		for i := range shares {
+			y := evaluate(coefficients, big.NewInt(int64(shares[i].Index)))
+			shares[i].Values = append(shares[i].Values, y)
+		}
+
+		if end == len(secret) {
+			break
+		}
+	}
+
+	return shares, nil
+}
+
+// Combine reconstructs the secret from at least Threshold distinct shares
+// by Lagrange interpolation at x = 0.
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("shamir Combine | no shares")
+	}
+
+	first := shares[0]
+	if first.Threshold < 2 {
+		return nil, errors.New("shamir Combine | threshold must be at least 2")
+	}
+	if len(first.Values) != chunkCount(first.Length) {
+		return nil, errors.New("shamir Combine | share length does not match its values")
+	}
+
+	// every share is checked, not only the Threshold ones used below, so a
+	// share of another secret is reported instead of silently dropped
+	seen := make(map[int]bool)
+	for _, s := range shares {
+		if s.Threshold != first.Threshold || s.Length != first.Length || len(s.Values) != len(first.Values) {
+			return nil, errors.New("shamir Combine | shares belong to different secrets")
+		}
+		if s.Index < 1 || seen[s.Index] {
+			return nil, errors.New("shamir Combine | duplicate or invalid share index")
+		}
+		seen[s.Index] = true
+	}
+
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("shamir Combine | need %d shares, got %d", first.Threshold, len(shares))
+	}
+	shares = shares[:first.Threshold]
+
+	secret := make([]byte, 0, first.Length)
+	for c := range first.Values {
+		value := interpolateAtZero(shares, c)
+
+		size := ChunkSize
+		if remaining := first.Length - len(secret); remaining < size {
+			size = remaining
+		}
+		if value.BitLen() > 8*size {
+			return nil, errors.New("shamir Combine | shares are inconsistent")
+		}
+
+		chunk := make([]byte, size)
+		value.FillBytes(chunk)
+		secret = append(secret, chunk...)
+	}
+
+	return secret, nil
+}
+
+// SplitRSAKey splits the private exponent D of key into n shares, k of which
+// are needed to rebuild the key with CombineRSAKey.
+func SplitRSAKey(key ciphers.RSA, n, k int) ([]Share, error) {
+	if key.D == nil {
+		return nil, errors.New("shamir SplitRSAKey | key has no private exponent")
+	}
+	return Split(key.D.Bytes(), n, k)
+}
+
+// CombineRSAKey restores the private exponent of pub from shares.
+func CombineRSAKey(pub ciphers.RSA, shares []Share) (ciphers.RSA, error) {
+	d, err := Combine(shares)
+	if err != nil {
+		return ciphers.RSA{}, err
+	}
+
+	return ciphers.RSA{N: pub.N, E: pub.E, D: new(big.Int).SetBytes(d)}, nil
+}
+
+// Encode serializes the share as
+// shamir-v1:<threshold>:<index>:<length>:<hex value>[.<hex value>...]
+func (s Share) Encode() string {
+	values := make([]string, len(s.Values))
+	for i, v := range s.Values {
+		values[i] = v.Text(16)
+	}
+
+	return strings.Join([]string{
+		encodingPrefix,
+		strconv.Itoa(s.Threshold),
+		strconv.Itoa(s.Index),
+		strconv.Itoa(s.Length),
+		strings.Join(values, "."),
+	}, ":")
+}
+
+// Decode parses a share produced by Encode.
+func Decode(encoded string) (Share, error) {
+	parts := strings.Split(encoded, ":")
+	if len(parts) != 5 || parts[0] != encodingPrefix {
+		return Share{}, errors.New("shamir Decode | invalid share format")
+	}
+
+	var numbers [3]int
+	for i := range numbers {
+		number, err := strconv.Atoi(parts[i+1])
+		if err != nil || number < 0 {
+			return Share{}, errors.New("shamir Decode | invalid share header")
+		}
+		numbers[i] = number
+	}
+
+	s := Share{Threshold: numbers[0], Index: numbers[1], Length: numbers[2]}
+	if s.Threshold < 2 || s.Index < 1 {
+		return Share{}, errors.New("shamir Decode | invalid share header")
+	}
+	for _, hex := range strings.Split(parts[4], ".") {
+		v, ok := new(big.Int).SetString(hex, 16)
+		if !ok || v.Sign() < 0 || v.Cmp(Prime) >= 0 {
+			return Share{}, errors.New("shamir Decode | invalid share value")
+		}
+		s.Values = append(s.Values, v)
+	}
+
+	if len(s.Values) != chunkCount(s.Length) {
+		return Share{}, errors.New("shamir Decode | share length does not match its values")
+	}
+
+	return s, nil
+}
+
+func (s Share) MarshalText() ([]byte, error) {
+	return []byte(s.Encode()), nil
+}
+
+func (s *Share) UnmarshalText(text []byte) error {
+	decoded, err := Decode(string(text))
+	if err != nil {
+		return err
+	}
+	*s = decoded
+	return nil
+}
+
+// chunkCount is the number of values Split produces for a secret of length
+// bytes, an empty secret still gets one.
+func chunkCount(length int) int {
+	if length == 0 {
+		return 1
+	}
+	return (length + ChunkSize - 1) / ChunkSize
+}
+
+// evaluate computes f(x) mod Prime with Horner's method.
+func evaluate(coefficients []*big.Int, x *big.Int) *big.Int {
+	y := new(big.Int)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y.Mul(y, x).Add(y, coefficients[i]).Mod(y, Prime)
+	}
+	return y
+}
+
+// interpolateAtZero computes f(0) = sum y_i * prod x_j / (x_j - x_i) mod Prime
+// for chunk c of the shares.
+func interpolateAtZero(shares []Share, c int) *big.Int {
+	result := new(big.Int)
+	for i, si := range shares {
+		num, den := big.NewInt(1), big.NewInt(1)
+		xi := big.NewInt(int64(si.Index))
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			xj := big.NewInt(int64(sj.Index))
+			num.Mul(num, xj).Mod(num, Prime)
+			den.Mul(den, new(big.Int).Sub(xj, xi)).Mod(den, Prime)
+		}
+
+		term := new(big.Int).Mul(si.Values[c], num)
+		term.Mul(term, new(big.Int).ModInverse(den, Prime))
+		result.Add(result, term).Mod(result, Prime)
+	}
+	return result
+}
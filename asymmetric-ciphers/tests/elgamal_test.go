@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+	"github.com/darkcat013/cs-labs/asymmetric-ciphers/interfaces"
+)
+
+func TestElGamalEncryptDecrypt(t *testing.T) {
+	//Arrange
+	elGamal, err := ciphers.GenerateElGamalKeys(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c interfaces.Cipher = elGamal
+	msg := "hello world"
+	expectedDec := "hello world"
+
+	//Act
+	enc := c.EncryptMessage(msg)
+	dec := c.DecryptMessage(enc)
+
+	//Assert
+	if dec != expectedDec {
+		t.Fatalf("Expected decrypted '%s', got '%s'", expectedDec, dec)
+	}
+}
+
+func TestElGamalMultiplicativeHomomorphism(t *testing.T) {
+	//Arrange
+	elGamal, err := ciphers.GenerateElGamalKeys(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m1 := big.NewInt(1234)
+	m2 := big.NewInt(5678)
+	expected := big.NewInt(1234 * 5678)
+
+	c1, err := elGamal.Encrypt(m1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := elGamal.Encrypt(m2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	product, err := elGamal.Decrypt(elGamal.Multiply(c1, c2))
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Decrypt error should be nil, got %s", err.Error())
+	}
+	if product.Cmp(expected) != 0 {
+		t.Fatalf("Expected decrypted product '%s', got '%s'", expected, product)
+	}
+}
+
+func TestElGamalEncryptIsRandomized(t *testing.T) {
+	//Arrange
+	elGamal, err := ciphers.GenerateElGamalKeys(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := "hello world"
+
+	//Act
+	enc1 := elGamal.EncryptMessage(msg)
+	enc2 := elGamal.EncryptMessage(msg)
+
+	//Assert
+	if enc1 == enc2 {
+		t.Fatalf("Expected two encryptions of the same message to differ")
+	}
+}
+
+func TestElGamalRejectsOutOfRange(t *testing.T) {
+	//Arrange
+	elGamal, err := ciphers.GenerateElGamalKeys(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := (elGamal.P.BitLen() + 7) / 8
+	zeroC1 := string(make([]byte, size)) + string(elGamal.P.Bytes())
+
+	//Act
+	_, encErr := elGamal.Encrypt(new(big.Int).Add(elGamal.P, big.NewInt(5)))
+	_, zeroErr := elGamal.Decrypt(ciphers.ElGamalCiphertext{C1: big.NewInt(0), C2: big.NewInt(1)})
+	_, largeErr := elGamal.Decrypt(ciphers.ElGamalCiphertext{C1: big.NewInt(1), C2: elGamal.P})
+	dec := elGamal.DecryptMessage(zeroC1)
+
+	//Assert
+	if encErr == nil {
+		t.Errorf("Encrypt error for a message >= p should not be nil")
+	}
+	if zeroErr == nil || largeErr == nil {
+		t.Errorf("Decrypt error for a ciphertext out of range should not be nil")
+	}
+	if dec != "" {
+		t.Errorf("Expected empty decryption of an invalid ciphertext, got '%s'", dec)
+	}
+}
+
+func TestElGamalCiphertextHidesResiduosity(t *testing.T) {
+	//Arrange
+	elGamal, err := ciphers.GenerateElGamalKeys(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := new(big.Int).Rsh(elGamal.P, 1)
+	isResidue := func(v *big.Int) bool {
+		return new(big.Int).Exp(v, q, elGamal.P).Cmp(big.NewInt(1)) == 0
+	}
+
+	for m := int64(2); m < 40; m++ {
+		message := big.NewInt(m)
+
+		//Act
+		c, err := elGamal.Encrypt(message)
+		if err != nil {
+			t.Fatalf("Encrypt error should be nil, got %s", err.Error())
+		}
+		dec, err := elGamal.Decrypt(c)
+
+		//Assert
+		if !isResidue(c.C2) {
+			t.Errorf("Expected c2 of %d to be a quadratic residue", m)
+		}
+		if err != nil || dec.Cmp(message) != 0 {
+			t.Errorf("Expected decrypted '%d', got '%v' (%v)", m, dec, err)
+		}
+	}
+	if _, err := elGamal.Encrypt(new(big.Int).Add(q, big.NewInt(1))); err == nil {
+		t.Errorf("Encrypt error for a message > q should not be nil")
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+	"github.com/darkcat013/cs-labs/asymmetric-ciphers/interfaces"
+)
+
+func TestPaillierEncryptDecrypt(t *testing.T) {
+	//Arrange
+	paillier, err := ciphers.GeneratePaillierKeys(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c interfaces.Cipher = paillier
+	msg := "hello world"
+	expectedDec := "hello world"
+
+	//Act
+	enc := c.EncryptMessage(msg)
+	dec := c.DecryptMessage(enc)
+
+	//Assert
+	if dec != expectedDec {
+		t.Fatalf("Expected decrypted '%s', got '%s'", expectedDec, dec)
+	}
+}
+
+func TestPaillierAdditiveHomomorphism(t *testing.T) {
+	//Arrange
+	paillier, err := ciphers.GeneratePaillierKeys(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m1 := big.NewInt(1234)
+	m2 := big.NewInt(5678)
+	expectedSum := big.NewInt(1234 + 5678)
+	expectedPlainSum := big.NewInt(1234 + 10)
+	expectedPlainProduct := big.NewInt(1234 * 3)
+
+	c1, err := paillier.Encrypt(m1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := paillier.Encrypt(m2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	sum := paillier.Decrypt(paillier.Add(c1, c2))
+	plainSum := paillier.Decrypt(paillier.AddPlain(c1, big.NewInt(10)))
+	plainProduct := paillier.Decrypt(paillier.MultiplyPlain(c1, big.NewInt(3)))
+
+	//Assert
+	if sum.Cmp(expectedSum) != 0 {
+		t.Errorf("Expected decrypted sum '%s', got '%s'", expectedSum, sum)
+	}
+	if plainSum.Cmp(expectedPlainSum) != 0 {
+		t.Errorf("Expected decrypted plain sum '%s', got '%s'", expectedPlainSum, plainSum)
+	}
+	if plainProduct.Cmp(expectedPlainProduct) != 0 {
+		t.Errorf("Expected decrypted plain product '%s', got '%s'", expectedPlainProduct, plainProduct)
+	}
+}
+
+func TestPaillierEncryptOutOfRange(t *testing.T) {
+	//Arrange
+	paillier, err := ciphers.GeneratePaillierKeys(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	_, err = paillier.Encrypt(paillier.N)
+
+	//Assert
+	if err == nil {
+		t.Fatalf("Encrypt error should not be nil")
+	}
+}
+
+func TestPaillierEncryptMessageTooLong(t *testing.T) {
+	//Arrange
+	paillier, err := ciphers.GeneratePaillierKeys(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := strings.Repeat("too long for the modulus ", 4)
+
+	//Act
+	enc := paillier.EncryptMessage(msg)
+
+	//Assert
+	if enc != "" {
+		t.Fatalf("Expected empty encryption of a message >= n")
+	}
+}
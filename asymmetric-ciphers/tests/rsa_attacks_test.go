@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+	"github.com/darkcat013/cs-labs/asymmetric-ciphers/rsaattacks"
+)
+
+// rsaWithExponent builds a key with the fixed public exponent e.
+func rsaWithExponent(t *testing.T, bits int, e int64) ciphers.RSA {
+	t.Helper()
+	for {
+		p, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		key, ok := rsaFromFactors(p, q, big.NewInt(e))
+		if ok {
+			return key
+		}
+	}
+}
+
+func rsaFromFactors(p, q, e *big.Int) (ciphers.RSA, bool) {
+	one := big.NewInt(1)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	d := new(big.Int).ModInverse(e, phi)
+	if d == nil {
+		return ciphers.RSA{}, false
+	}
+	return ciphers.RSA{N: new(big.Int).Mul(p, q), E: e, D: d}, true
+}
+
+func TestSmallExponentAttack(t *testing.T) {
+	//Arrange
+	key := rsaWithExponent(t, 1024, 3)
+	pub := ciphers.RSA{N: key.N, E: key.E}
+	msg := "hi"
+	c := new(big.Int).SetBytes([]byte(key.EncryptMessage(msg)))
+
+	//Act
+	m, err := rsaattacks.SmallExponent(pub, c)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("SmallExponent error should be nil, got %s", err.Error())
+	}
+	if string(m.Bytes()) != msg {
+		t.Fatalf("Expected recovered '%s', got '%s'", msg, string(m.Bytes()))
+	}
+}
+
+func TestCommonModulusAttack(t *testing.T) {
+	//Arrange
+	key1 := rsaWithExponent(t, 1024, 65537)
+	key2 := ciphers.RSA{N: key1.N, E: big.NewInt(17)}
+	msg := "common modulus"
+	m := new(big.Int).SetBytes([]byte(msg))
+	c1 := new(big.Int).Exp(m, key1.E, key1.N)
+	c2 := new(big.Int).Exp(m, key2.E, key2.N)
+
+	//Act
+	recovered, err := rsaattacks.CommonModulus(ciphers.RSA{N: key1.N, E: key1.E}, key2, c1, c2)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("CommonModulus error should be nil, got %s", err.Error())
+	}
+	if recovered.Cmp(m) != 0 {
+		t.Fatalf("Expected recovered '%s', got '%s'", msg, string(recovered.Bytes()))
+	}
+}
+
+func TestWienerAttack(t *testing.T) {
+	//Arrange
+	var key ciphers.RSA
+	for {
+		p, _ := rand.Prime(rand.Reader, 512)
+		q, _ := rand.Prime(rand.Reader, 512)
+		// d well below n^(1/4) / 3
+		d, _ := rand.Prime(rand.Reader, 200)
+		inverse, ok := rsaFromFactors(p, q, d)
+		if ok {
+			key = ciphers.RSA{N: inverse.N, E: inverse.D, D: d}
+			break
+		}
+	}
+
+	//Act
+	recovered, err := rsaattacks.Wiener(ciphers.RSA{N: key.N, E: key.E})
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Wiener error should be nil, got %s", err.Error())
+	}
+	if recovered.D.Cmp(key.D) != 0 {
+		t.Fatalf("Expected recovered d '%s', got '%s'", key.D, recovered.D)
+	}
+}
+
+func TestWienerAttackFailsOnLargeD(t *testing.T) {
+	//Arrange
+	key := rsaWithExponent(t, 1024, 65537)
+
+	//Act
+	_, err := rsaattacks.Wiener(ciphers.RSA{N: key.N, E: key.E})
+
+	//Assert
+	if err == nil {
+		t.Fatalf("Wiener error should not be nil")
+	}
+}
+
+func TestFermatAttack(t *testing.T) {
+	//Arrange
+	p, _ := rand.Prime(rand.Reader, 512)
+	q := new(big.Int).Add(p, big.NewInt(2))
+	for !q.ProbablyPrime(20) {
+		q.Add(q, big.NewInt(2))
+	}
+	key, ok := rsaFromFactors(p, q, big.NewInt(65537))
+	if !ok {
+		t.Skip("65537 is not invertible for the generated primes")
+	}
+
+	//Act
+	recovered, err := rsaattacks.Fermat(ciphers.RSA{N: key.N, E: key.E}, 1000)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Fermat error should be nil, got %s", err.Error())
+	}
+	if recovered.D.Cmp(key.D) != 0 {
+		t.Fatalf("Expected recovered d '%s', got '%s'", key.D, recovered.D)
+	}
+}
+
+func TestPollardPMinus1Attack(t *testing.T) {
+	//Arrange
+	// p - 1 is a product of primes below 50, so a bound of 1000 is enough
+	smallPrimes := []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47}
+	var p *big.Int
+	for p == nil {
+		candidate := big.NewInt(2)
+		for candidate.BitLen() < 256 {
+			i, _ := rand.Int(rand.Reader, big.NewInt(int64(len(smallPrimes))))
+			candidate.Mul(candidate, big.NewInt(smallPrimes[i.Int64()]))
+		}
+		candidate.Add(candidate, big.NewInt(1))
+		if candidate.ProbablyPrime(20) {
+			p = candidate
+		}
+	}
+	q, _ := rand.Prime(rand.Reader, 512)
+	key, ok := rsaFromFactors(p, q, big.NewInt(65537))
+	if !ok {
+		t.Skip("65537 is not invertible for the generated primes")
+	}
+
+	//Act
+	recovered, err := rsaattacks.PollardPMinus1(ciphers.RSA{N: key.N, E: key.E}, 1000)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("PollardPMinus1 error should be nil, got %s", err.Error())
+	}
+	if recovered.D.Cmp(key.D) != 0 {
+		t.Fatalf("Expected recovered d '%s', got '%s'", key.D, recovered.D)
+	}
+}
+
+func TestHastadAttack(t *testing.T) {
+	//Arrange
+	msg := "broadcast message"
+	m := new(big.Int).SetBytes([]byte(msg))
+
+	pubs := make([]ciphers.RSA, 3)
+	cs := make([]*big.Int, 3)
+	for i := range pubs {
+		key := rsaWithExponent(t, 512, 3)
+		pubs[i] = ciphers.RSA{N: key.N, E: key.E}
+		cs[i] = new(big.Int).Exp(m, key.E, key.N)
+	}
+
+	//Act
+	recovered, err := rsaattacks.Hastad(pubs, cs)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Hastad error should be nil, got %s", err.Error())
+	}
+	if recovered.Cmp(m) != 0 {
+		t.Fatalf("Expected recovered '%s', got '%s'", msg, string(recovered.Bytes()))
+	}
+}
+
+func TestAttacksRejectBadExponents(t *testing.T) {
+	//Arrange
+	n := new(big.Int).Lsh(big.NewInt(1), 512)
+	c := big.NewInt(8)
+	huge := new(big.Int).Lsh(big.NewInt(1), 40)
+
+	//Act
+	_, zeroErr := rsaattacks.SmallExponent(ciphers.RSA{N: n, E: big.NewInt(0)}, c)
+	_, negativeErr := rsaattacks.SmallExponent(ciphers.RSA{N: n, E: big.NewInt(-3)}, c)
+	_, hugeErr := rsaattacks.SmallExponent(ciphers.RSA{N: n, E: huge}, c)
+	_, hastadErr := rsaattacks.Hastad([]ciphers.RSA{{N: n, E: big.NewInt(0)}}, []*big.Int{c})
+	_, _, rootErr := rsaattacks.Root(c, 0)
+
+	//Assert
+	if zeroErr == nil || negativeErr == nil || hugeErr == nil {
+		t.Errorf("SmallExponent error for an exponent out of range should not be nil")
+	}
+	if hastadErr == nil {
+		t.Errorf("Hastad error for a zero exponent should not be nil")
+	}
+	if rootErr == nil {
+		t.Errorf("Root error for a zero exponent should not be nil")
+	}
+}
+
+func TestRootLargeExponent(t *testing.T) {
+	//Act
+	one, oneExact, oneErr := rsaattacks.Root(big.NewInt(1), 1000)
+	r, exact, err := rsaattacks.Root(big.NewInt(8), 3)
+	small, smallExact, _ := rsaattacks.Root(big.NewInt(9), 64)
+
+	//Assert
+	if oneErr != nil || err != nil {
+		t.Fatalf("Root errors should be nil, got %v, %v", oneErr, err)
+	}
+	if one.Int64() != 1 || !oneExact || r.Int64() != 2 || !exact {
+		t.Errorf("Expected exact roots 1 and 2, got %s and %s", one, r)
+	}
+	if small.Int64() != 1 || smallExact {
+		t.Errorf("Expected inexact root 1 of 9, got %s", small)
+	}
+}
+
+func TestCRT(t *testing.T) {
+	//Arrange
+	rs := []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(2)}
+	moduli := []*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)}
+
+	//Act
+	x, err := rsaattacks.CRT(rs, moduli)
+	_, shortErr := rsaattacks.CRT(rs[:2], moduli)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("CRT error should be nil, got %s", err.Error())
+	}
+	if x.Int64() != 23 {
+		t.Errorf("Expected 23, got %s", x)
+	}
+	if shortErr == nil {
+		t.Errorf("CRT error for fewer remainders than moduli should not be nil")
+	}
+}
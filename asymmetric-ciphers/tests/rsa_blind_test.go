@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"math/big"
+	"testing"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+)
+
+func TestBlindSignatureEndToEnd(t *testing.T) {
+	//Arrange
+	signer, err := ciphers.GenerateRSAKeys(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ciphers.RSA{N: signer.N, E: signer.E}
+	msg := []byte("anonymous voting credential")
+
+	//Act
+	blinded, inv, err := client.Blind(rand.Reader, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blindSig, err := signer.BlindSign(blinded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := client.Finalize(msg, blindSig, inv)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Finalize error should be nil, got %s", err.Error())
+	}
+	if err := client.VerifyBlindSignature(msg, sig); err != nil {
+		t.Fatalf("VerifyBlindSignature error should be nil, got %s", err.Error())
+	}
+	if bytes.Equal(blindSig, sig) {
+		t.Fatalf("Expected signer response to differ from the final signature")
+	}
+}
+
+func TestBlindSignatureWrongMessage(t *testing.T) {
+	//Arrange
+	signer, err := ciphers.GenerateRSAKeys(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("anonymous voting credential")
+	blinded, inv, _ := signer.Blind(rand.Reader, msg)
+	blindSig, _ := signer.BlindSign(blinded)
+	sig, err := signer.Finalize(msg, blindSig, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	err = signer.VerifyBlindSignature([]byte("another credential"), sig)
+
+	//Assert
+	if err == nil {
+		t.Fatalf("VerifyBlindSignature error should not be nil")
+	}
+}
+
+func TestBlindingIsUnlinkable(t *testing.T) {
+	//Arrange
+	signer, err := ciphers.GenerateRSAKeys(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("anonymous voting credential")
+
+	//Act
+	blinded1, _, _ := signer.Blind(rand.Reader, msg)
+	blinded2, _, _ := signer.Blind(rand.Reader, msg)
+
+	//Assert
+	if bytes.Equal(blinded1, blinded2) {
+		t.Fatalf("Expected two blindings of the same message to differ")
+	}
+}
+
+func TestBlindSignatureIsStandardPSS(t *testing.T) {
+	//Arrange
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := ciphers.RSA{N: key.N, E: big.NewInt(int64(key.E)), D: key.D}
+	msg := []byte("anonymous voting credential")
+
+	blinded, inv, _ := signer.Blind(rand.Reader, msg)
+	blindSig, _ := signer.BlindSign(blinded)
+	sig, err := signer.Finalize(msg, blindSig, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed := sha512.Sum384(msg)
+
+	//Act
+	err = rsa.VerifyPSS(&key.PublicKey, crypto.SHA384, hashed[:], sig, &rsa.PSSOptions{SaltLength: ciphers.BlindSaltLength})
+
+	//Assert
+	if err != nil {
+		t.Fatalf("VerifyPSS error should be nil, got %s", err.Error())
+	}
+}
@@ -9,7 +9,7 @@ import (
 
 func TestEncryptDecrypt(t *testing.T) {
 	//Arrange
-	rsa, err := ciphers.GenerateRSAKeys(rand.Reader, 1024)
+	rsa, err := ciphers.GenerateRSAKeys(rand.Reader, 2048)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+)
+
+func hasFinding(findings []ciphers.KeyFinding, code ciphers.KeyFindingCode) bool {
+	for _, f := range findings {
+		if f.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGeneratedKeyHasNoCriticalFindings(t *testing.T) {
+	//Arrange
+	rsa, err := ciphers.GenerateRSAKeys(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	findings := ciphers.ValidateRSAKey(rsa, 2048)
+
+	//Assert
+	if ciphers.HasCriticalFindings(findings) {
+		t.Fatalf("Expected no critical findings, got %v", findings)
+	}
+}
+
+func TestGenerateRefusesSmallModulus(t *testing.T) {
+	//Act
+	_, err := ciphers.GenerateRSAKeys(rand.Reader, 512)
+
+	//Assert
+	var weakKeyErr *ciphers.WeakKeyError
+	if !errors.As(err, &weakKeyErr) {
+		t.Fatalf("Expected WeakKeyError, got %v", err)
+	}
+	if !hasFinding(weakKeyErr.Findings, ciphers.FindingModulusTooSmall) {
+		t.Fatalf("Expected finding '%s', got %v", ciphers.FindingModulusTooSmall, weakKeyErr.Findings)
+	}
+}
+
+func TestGenerateAllowWeakOverride(t *testing.T) {
+	//Act
+	rsa, err := ciphers.GenerateRSAKeysWithOptions(rand.Reader, 512, ciphers.RSAKeyOptions{AllowWeak: true})
+
+	//Assert
+	if err != nil {
+		t.Fatalf("GenerateRSAKeysWithOptions error should be nil, got %s", err.Error())
+	}
+	if rsa.N.BitLen() != 512 {
+		t.Fatalf("Expected 512 bit modulus, got %d", rsa.N.BitLen())
+	}
+}
+
+func TestGenerateOddBitLength(t *testing.T) {
+	//Act
+	rsa, err := ciphers.GenerateRSAKeys(rand.Reader, 2049)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("GenerateRSAKeys error should be nil, got %s", err.Error())
+	}
+	if rsa.N.BitLen() != 2049 {
+		t.Fatalf("Expected 2049 bit modulus, got %d", rsa.N.BitLen())
+	}
+}
+
+func TestValidateWrongBitLength(t *testing.T) {
+	//Arrange
+	rsa, err := ciphers.GenerateRSAKeys(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	findings := ciphers.ValidateRSAKey(rsa, 2049)
+
+	//Assert
+	if !hasFinding(findings, ciphers.FindingModulusBitLength) {
+		t.Fatalf("Expected finding '%s', got %v", ciphers.FindingModulusBitLength, findings)
+	}
+}
+
+func TestValidateEqualAndClosePrimes(t *testing.T) {
+	//Arrange
+	p, _ := rand.Prime(rand.Reader, 1024)
+	q := new(big.Int).Add(p, big.NewInt(2))
+	for !q.ProbablyPrime(20) {
+		q.Add(q, big.NewInt(2))
+	}
+	e := big.NewInt(ciphers.StandardRSAExponent)
+	equal := ciphers.RSA{N: new(big.Int).Mul(p, p), E: e, P: p, Q: p}
+	closePrimes := ciphers.RSA{N: new(big.Int).Mul(p, q), E: e, P: p, Q: q}
+
+	//Act
+	equalFindings := ciphers.ValidateRSAKey(equal, 0)
+	closeFindings := ciphers.ValidateRSAKey(closePrimes, 0)
+
+	//Assert
+	if !hasFinding(equalFindings, ciphers.FindingEqualPrimes) {
+		t.Errorf("Expected finding '%s', got %v", ciphers.FindingEqualPrimes, equalFindings)
+	}
+	if !hasFinding(closeFindings, ciphers.FindingClosePrimes) {
+		t.Errorf("Expected finding '%s', got %v", ciphers.FindingClosePrimes, closeFindings)
+	}
+}
+
+func TestValidateSmallPrivateExponent(t *testing.T) {
+	//Arrange
+	p, _ := rand.Prime(rand.Reader, 1024)
+	q, _ := rand.Prime(rand.Reader, 1024)
+	one := big.NewInt(1)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	d := big.NewInt(65537)
+	e := new(big.Int).ModInverse(d, phi)
+	if e == nil {
+		t.Skip("65537 is not invertible for the generated primes")
+	}
+	rsa := ciphers.RSA{N: new(big.Int).Mul(p, q), E: e, D: d, P: p, Q: q}
+
+	//Act
+	findings := ciphers.ValidateRSAKey(rsa, 2048)
+
+	//Assert
+	if !hasFinding(findings, ciphers.FindingSmallPrivateExponent) {
+		t.Errorf("Expected finding '%s', got %v", ciphers.FindingSmallPrivateExponent, findings)
+	}
+	if !hasFinding(findings, ciphers.FindingNonStandardExponent) {
+		t.Errorf("Expected finding '%s', got %v", ciphers.FindingNonStandardExponent, findings)
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	ciphers "github.com/darkcat013/cs-labs/asymmetric-ciphers"
+	"github.com/darkcat013/cs-labs/asymmetric-ciphers/shamir"
+)
+
+// lagrange evaluates at x the polynomial through the given points over shamir.Prime.
+func lagrange(xs []int64, ys []*big.Int, x int64) *big.Int {
+	result := new(big.Int)
+	for i := range xs {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num.Mul(num, big.NewInt(x-xs[j]))
+			den.Mul(den, big.NewInt(xs[i]-xs[j]))
+		}
+		term := new(big.Int).Mul(ys[i], num)
+		term.Mul(term, new(big.Int).ModInverse(den.Mod(den, shamir.Prime), shamir.Prime))
+		result.Add(result, term).Mod(result, shamir.Prime)
+	}
+	return result
+}
+
+func TestShamirSplitCombine(t *testing.T) {
+	//Arrange
+	secret := make([]byte, 200)
+	rand.Read(secret)
+	secret[0] = 0
+
+	shares, err := shamir.Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	fromFirst, err1 := shamir.Combine(shares[:3])
+	fromLast, err2 := shamir.Combine([]shamir.Share{shares[4], shares[1], shares[3]})
+
+	//Assert
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Combine errors should be nil, got %v, %v", err1, err2)
+	}
+	if !bytes.Equal(fromFirst, secret) || !bytes.Equal(fromLast, secret) {
+		t.Fatalf("Expected combined shares to equal the secret")
+	}
+}
+
+func TestShamirTooFewShares(t *testing.T) {
+	//Arrange
+	shares, err := shamir.Split([]byte("secret"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	_, err = shamir.Combine(shares[:2])
+
+	//Assert
+	if err == nil {
+		t.Fatalf("Combine error should not be nil")
+	}
+}
+
+func TestShamirKMinusOneSharesRevealNothing(t *testing.T) {
+	//Arrange
+	secret := []byte("the real secret")
+	candidate := []byte("any other value")
+	shares, err := shamir.Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	known := shares[:2]
+
+	// Every candidate secret is consistent with k-1 shares: there is a
+	// polynomial through (0, candidate) and the known shares, and a third
+	// share taken from it combines with them to the candidate.
+	xs := []int64{0, int64(known[0].Index), int64(known[1].Index)}
+	ys := []*big.Int{new(big.Int).SetBytes(candidate), known[0].Values[0], known[1].Values[0]}
+	forged := shamir.Share{
+		Index:     5,
+		Threshold: 3,
+		Length:    len(candidate),
+		Values:    []*big.Int{lagrange(xs, ys, 5)},
+	}
+
+	//Act
+	combined, err := shamir.Combine(append([]shamir.Share{forged}, known...))
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Combine error should be nil, got %s", err.Error())
+	}
+	if !bytes.Equal(combined, candidate) {
+		t.Fatalf("Expected '%s', got '%s'", candidate, combined)
+	}
+}
+
+func TestShamirEncodeDecode(t *testing.T) {
+	//Arrange
+	shares, err := shamir.Split([]byte("serialize me, please, across more than one chunk of sixty-four bytes"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := shares[1].Encode()
+
+	//Act
+	decoded, err := shamir.Decode(expected)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Decode error should be nil, got %s", err.Error())
+	}
+	if decoded.Encode() != expected {
+		t.Fatalf("Expected '%s', got '%s'", expected, decoded.Encode())
+	}
+}
+
+func TestShamirRSAKey(t *testing.T) {
+	//Arrange
+	rsa, err := ciphers.GenerateRSAKeys(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := ciphers.RSA{N: rsa.N, E: rsa.E}
+	msg := "hello world"
+	enc := rsa.EncryptMessage(msg)
+
+	shares, err := shamir.SplitRSAKey(rsa, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	restored, err := shamir.CombineRSAKey(pub, shares[2:])
+
+	//Assert
+	if err != nil {
+		t.Fatalf("CombineRSAKey error should be nil, got %s", err.Error())
+	}
+	if dec := restored.DecryptMessage(enc); dec != msg {
+		t.Fatalf("Expected decrypted '%s', got '%s'", msg, dec)
+	}
+}
+
+func TestShamirRejectsThresholdBelowTwo(t *testing.T) {
+	//Arrange
+	share := shamir.Share{Index: 1, Threshold: 1, Length: 1, Values: []*big.Int{big.NewInt(42)}}
+
+	//Act
+	_, decodeErr := shamir.Decode(share.Encode())
+	_, combineErr := shamir.Combine([]shamir.Share{share})
+
+	//Assert
+	if decodeErr == nil {
+		t.Errorf("Decode error for threshold 1 should not be nil")
+	}
+	if combineErr == nil {
+		t.Errorf("Combine error for threshold 1 should not be nil")
+	}
+}
+
+func TestShamirRejectsSharesOfAnotherSecret(t *testing.T) {
+	//Arrange
+	shares, err := shamir.Split([]byte("secret"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := shamir.Split([]byte("another secret"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	_, err = shamir.Combine(append(shares[:3], other[3]))
+
+	//Assert
+	if err == nil {
+		t.Fatalf("Combine error for a share of another secret should not be nil")
+	}
+}
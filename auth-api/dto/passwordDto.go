@@ -0,0 +1,16 @@
+package dto
+
+type ChangePasswordDto struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+type ResetPasswordDto struct {
+	Email       string `json:"email"`
+	Otp         string `json:"otp"`
+	NewPassword string `json:"newPassword"`
+}
+
+type DeleteAccountDto struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,104 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"time"
+
+	josejwt "github.com/go-jose/go-jose/v3/jwt"
+)
+
+const (
+	DefaultIssuer    = "cs-labs-auth-api"
+	DefaultAudience  = "cs-labs"
+	DefaultClockSkew = 30 * time.Second
+)
+
+// Options are what tokens are issued for and validated against.
+type Options struct {
+	Issuer   string
+	Audience string
+	// ClockSkew is how far the clocks of the issuer and of us may drift.
+	ClockSkew time.Duration
+}
+
+// LoadOptions reads JWT_ISSUER, JWT_AUDIENCE and JWT_CLOCK_SKEW (a duration
+// like 30s) from the environment.
+func LoadOptions() (Options, error) {
+	options := Options{Issuer: DefaultIssuer, Audience: DefaultAudience, ClockSkew: DefaultClockSkew}
+
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		options.Issuer = issuer
+	}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		options.Audience = audience
+	}
+	if skew := os.Getenv("JWT_CLOCK_SKEW"); skew != "" {
+		duration, err := time.ParseDuration(skew)
+		if err != nil || duration < 0 {
+			return Options{}, errors.New("jwt | JWT_CLOCK_SKEW is not a valid duration")
+		}
+		options.ClockSkew = duration
+	}
+
+	return options, nil
+}
+
+// Claims are the claims of an access token.
+type Claims struct {
+	josejwt.Claims
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// NewClaims returns the claims of a new access token for email.
+func NewClaims(email, role string, options Options, now time.Time) (*Claims, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errors.New("jwt | Could not generate token id")
+	}
+
+	return &Claims{
+		Claims: josejwt.Claims{
+			Issuer:    options.Issuer,
+			Subject:   email,
+			Audience:  josejwt.Audience{options.Audience},
+			Expiry:    josejwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			NotBefore: josejwt.NewNumericDate(now),
+			IssuedAt:  josejwt.NewNumericDate(now),
+			ID:        base64.RawURLEncoding.EncodeToString(id),
+		},
+		Email: email,
+		Role:  role,
+	}, nil
+}
+
+// Validate checks every claim, allowing the clock skew of the options on the
+// time based ones.
+func (c *Claims) Validate(options Options, now time.Time) error {
+	switch {
+	case c.Expiry == nil:
+		return errors.New("jwt | Token has no expiration")
+	case c.IssuedAt == nil:
+		return errors.New("jwt | Token has no issued at")
+	case c.ID == "":
+		return errors.New("jwt | Token has no id")
+	case c.Email == "" || c.Subject != c.Email:
+		return errors.New("jwt | Token subject is not valid")
+	case c.Role == "":
+		return errors.New("jwt | Token has no role")
+	}
+
+	expected := josejwt.Expected{
+		Issuer:   options.Issuer,
+		Audience: josejwt.Audience{options.Audience},
+		Time:     now,
+	}
+	if err := c.ValidateWithLeeway(expected, options.ClockSkew); err != nil {
+		return errors.New("jwt | Token is not valid, " + err.Error())
+	}
+
+	return nil
+}
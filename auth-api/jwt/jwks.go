@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/base64"
+	"sort"
+
+	"github.com/go-jose/go-jose/v3"
+)
+
+// JWKS returns the public keys tokens are verified with, for the document
+// served at /.well-known/jwks.json. HMAC secrets are never published.
+func (k *Keys) JWKS() jose.JSONWebKeySet {
+	set := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+
+	for _, key := range k.sortedVerificationKeys() {
+		if key.ID == "" {
+			continue
+		}
+
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       key.Public,
+			KeyID:     key.ID,
+			Algorithm: string(key.Algorithm),
+			Use:       "sig",
+		})
+	}
+
+	return set
+}
+
+// sortedVerificationKeys lists the signing key first, then the others by id.
+func (k *Keys) sortedVerificationKeys() []*Key {
+	keys := []*Key{k.Signing}
+	var ids []string
+	for id := range k.Verification {
+		if id != k.Signing.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		keys = append(keys, k.Verification[id])
+	}
+	return keys
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of a public key.
+func Thumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk := jose.JSONWebKey{Key: publicKey}
+
+	sum, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(sum), nil
+}
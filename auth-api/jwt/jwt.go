@@ -1,37 +1,148 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v3"
+	josejwt "github.com/go-jose/go-jose/v3/jwt"
 )
 
+// AccessTokenTTL is how long an access token is valid, clients renew it with
+// their refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
 func Generate(email, role string) (string, error) {
 
-	secret := os.Getenv("SECRET")
+	keys, err := DefaultKeys()
+	if err != nil {
+		return "", err
+	}
+
+	options, err := LoadOptions()
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := NewClaims(email, role, options, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	return keys.Sign(claims)
+}
+
+// Sign signs claims with the signing key, naming it in the kid header. With
+// an encryption key the signed token is then encrypted, as a nested JWT.
+func (k *Keys) Sign(claims interface{}) (string, error) {
+	signerOptions := (&jose.SignerOptions{}).WithType("JWT")
+	if k.Signing.ID != "" {
+		signerOptions = signerOptions.WithHeader("kid", k.Signing.ID)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: k.Signing.Algorithm, Key: k.Signing.Private}, signerOptions)
+	if err != nil {
+		return "", err
+	}
 
-	claims := jwt.MapClaims{}
-	claims["authorized"] = true
-	claims["email"] = email
-	claims["role"] = role
-	claims["exp"] = time.Now().Add(1 * time.Hour).Unix()
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if k.Encryption == nil {
+		return josejwt.Signed(signer).Claims(claims).CompactSerialize()
+	}
 
-	signedToken, err := jwtToken.SignedString([]byte(secret))
+	recipient := jose.Recipient{
+		Algorithm: k.Encryption.Algorithm,
+		Key:       k.Encryption.Private.Public(),
+		KeyID:     k.Encryption.ID,
+	}
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, recipient, (&jose.EncrypterOptions{}).WithType("JWT").WithContentType("JWT"))
 	if err != nil {
 		return "", err
 	}
 
-	return signedToken, nil
+	return josejwt.SignedAndEncrypted(signer, encrypter).Claims(claims).CompactSerialize()
 }
 
-func ExtractEmailAndRole(c *gin.Context) (string, string, error) {
+// Parse decrypts an encrypted token, checks the signature with the key the
+// kid header names and decodes it into claims, which are left for the caller
+// to validate. The algorithm has to be the one of that key, so a public key
+// is never used as an HMAC secret.
+func (k *Keys) Parse(authToken string, claims interface{}) error {
+	var token *josejwt.JSONWebToken
+	var err error
 
-	secret := os.Getenv("SECRET")
+	// a JWE in compact form has five parts, a JWS three
+	if strings.Count(authToken, ".") == 4 {
+		token, err = k.decrypt(authToken)
+	} else {
+		token, err = josejwt.ParseSigned(authToken)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(token.Headers) != 1 {
+		return errors.New("jwt | Token must have one signature")
+	}
+	header := token.Headers[0]
+
+	key, ok := k.Verification[header.KeyID]
+	if !ok {
+		return fmt.Errorf("jwt | Unknown key id: %v", header.KeyID)
+	}
+	if header.Algorithm != string(key.Algorithm) {
+		return fmt.Errorf("jwt | Unexpected signing method: %v", header.Algorithm)
+	}
+
+	return token.Claims(key.Public, claims)
+}
+
+func (k *Keys) decrypt(authToken string) (*josejwt.JSONWebToken, error) {
+	if k.Encryption == nil {
+		return nil, errors.New("jwt | Encrypted tokens are not accepted")
+	}
+
+	nested, err := josejwt.ParseSignedAndEncrypted(authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nested.Headers) != 1 || nested.Headers[0].Algorithm != string(k.Encryption.Algorithm) {
+		return nil, errors.New("jwt | Unexpected encryption algorithm")
+	}
+
+	return nested.Decrypt(k.Encryption.Private)
+}
+
+// Verify returns the claims of a valid access token.
+func Verify(authToken string) (*Claims, error) {
+	keys, err := DefaultKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := LoadOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	if err := keys.Parse(authToken, claims); err != nil {
+		return nil, err
+	}
+
+	if err := claims.Validate(options, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// ExtractClaims verifies the token of the request, given in the token query
+// parameter or as a bearer token.
+func ExtractClaims(c *gin.Context) (*Claims, error) {
 
 	authToken := c.Query("token")
 	if authToken == "" {
@@ -43,22 +154,9 @@ func ExtractEmailAndRole(c *gin.Context) (string, string, error) {
 		}
 	}
 
-	jwtToken, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
-	if err != nil {
-		return "", "", err
-	}
-
-	claims, ok := jwtToken.Claims.(jwt.MapClaims)
-	if ok && jwtToken.Valid {
-		email := claims["email"].(string)
-		role := claims["role"].(string)
-		return email, role, nil
+	if authToken == "" {
+		return nil, errors.New("jwt | Missing token")
 	}
 
-	return "", "", nil
+	return Verify(authToken)
 }
@@ -0,0 +1,291 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-jose/go-jose/v3"
+)
+
+// Key is a key tokens are signed or verified with. Asymmetric keys are
+// identified by their JWK thumbprint, which is sent in the kid header.
+type Key struct {
+	ID        string
+	Algorithm jose.SignatureAlgorithm
+	// Private is the signing key, nil for keys only kept to verify tokens.
+	Private interface{}
+	// Public verifies signatures, for HMAC it is the secret.
+	Public interface{}
+}
+
+// EncryptionKey encrypts tokens to ourselves, so the claims are only readable
+// by the API and not by the clients holding the tokens.
+type EncryptionKey struct {
+	ID        string
+	Algorithm jose.KeyAlgorithm
+	Private   crypto.Signer
+}
+
+// Keys signs new tokens with one key and accepts tokens of every key it
+// holds, so a new key can be rolled out while tokens of the previous one are
+// still valid.
+type Keys struct {
+	Signing      *Key
+	Verification map[string]*Key
+	// Encryption is optional, without it tokens are only signed.
+	Encryption *EncryptionKey
+}
+
+// NewHmacKeys signs and verifies tokens with HS256.
+func NewHmacKeys(secret []byte) (*Keys, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("jwt | HMAC secret cannot be empty")
+	}
+
+	key := &Key{Algorithm: jose.HS256, Private: secret, Public: secret}
+
+	return &Keys{Signing: key, Verification: map[string]*Key{"": key}}, nil
+}
+
+// NewKeys signs tokens with privateKey, an RSA (RS256), ECDSA P-256 (ES256),
+// P-384 (ES384) or Ed25519 (EdDSA) key, and also accepts tokens of the
+// previous public keys.
+func NewKeys(privateKey crypto.Signer, previous ...crypto.PublicKey) (*Keys, error) {
+	signing, err := newKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	signing.Private = privateKey
+
+	keys := &Keys{Signing: signing, Verification: map[string]*Key{signing.ID: signing}}
+
+	for _, publicKey := range previous {
+		key, err := newKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := keys.Verification[key.ID]; !ok {
+			keys.Verification[key.ID] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func newKey(publicKey crypto.PublicKey) (*Key, error) {
+	var algorithm jose.SignatureAlgorithm
+
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		algorithm = jose.RS256
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			algorithm = jose.ES256
+		case elliptic.P384():
+			algorithm = jose.ES384
+		default:
+			return nil, errors.New("jwt | Unsupported ECDSA curve")
+		}
+	case ed25519.PublicKey:
+		algorithm = jose.EdDSA
+	default:
+		return nil, errors.New("jwt | Unsupported key type")
+	}
+
+	id, err := Thumbprint(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{ID: id, Algorithm: algorithm, Public: publicKey}, nil
+}
+
+// NewEncryptionKey encrypts tokens with RSA-OAEP-256 for RSA keys or
+// ECDH-ES+A256KW for ECDSA keys, the content with A256GCM.
+func NewEncryptionKey(privateKey crypto.Signer) (*EncryptionKey, error) {
+	var algorithm jose.KeyAlgorithm
+
+	switch privateKey.Public().(type) {
+	case *rsa.PublicKey:
+		algorithm = jose.RSA_OAEP_256
+	case *ecdsa.PublicKey:
+		algorithm = jose.ECDH_ES_A256KW
+	default:
+		return nil, errors.New("jwt | Encryption key must be an RSA or ECDSA key")
+	}
+
+	id, err := Thumbprint(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptionKey{ID: id, Algorithm: algorithm, Private: privateKey}, nil
+}
+
+// LoadKeys configures the keys from the environment. JWT_PRIVATE_KEY_FILE is
+// a PEM private key to sign with, JWT_PUBLIC_KEY_FILES a comma separated list
+// of PEM public keys of previous signing keys. Without a private key tokens
+// are signed with HS256 and SECRET. JWT_ENCRYPTION_KEY_FILE is an optional
+// PEM private key tokens are encrypted to.
+func LoadKeys() (*Keys, error) {
+	keys, err := loadSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if encryptionKeyFile := os.Getenv("JWT_ENCRYPTION_KEY_FILE"); encryptionKeyFile != "" {
+		privateKey, err := readPrivateKey(encryptionKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keys.Encryption, err = NewEncryptionKey(privateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+func loadSigningKeys() (*Keys, error) {
+	privateKeyFile := os.Getenv("JWT_PRIVATE_KEY_FILE")
+	if privateKeyFile == "" {
+		if os.Getenv("JWT_PUBLIC_KEY_FILES") != "" {
+			return nil, errors.New("jwt | JWT_PUBLIC_KEY_FILES needs JWT_PRIVATE_KEY_FILE")
+		}
+		keys, err := NewHmacKeys([]byte(os.Getenv("SECRET")))
+		if err != nil {
+			return nil, errors.New("jwt | SECRET or JWT_PRIVATE_KEY_FILE must be set")
+		}
+		return keys, nil
+	}
+
+	privateKey, err := readPrivateKey(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []crypto.PublicKey
+	for _, file := range strings.Split(os.Getenv("JWT_PUBLIC_KEY_FILES"), ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+
+		publicKey, err := readPublicKey(file)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, publicKey)
+	}
+
+	return NewKeys(privateKey, previous...)
+}
+
+var (
+	defaultMu     sync.Mutex
+	defaultKeys   *Keys
+	defaultConfig string
+)
+
+// DefaultKeys returns the keys configured in the environment, they are loaded
+// again when the configuration changes.
+func DefaultKeys() (*Keys, error) {
+	config := strings.Join([]string{
+		os.Getenv("SECRET"),
+		os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		os.Getenv("JWT_PUBLIC_KEY_FILES"),
+		os.Getenv("JWT_ENCRYPTION_KEY_FILE"),
+	}, "\x00")
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultKeys == nil || config != defaultConfig {
+		keys, err := LoadKeys()
+		if err != nil {
+			return nil, err
+		}
+		defaultKeys, defaultConfig = keys, config
+	}
+
+	return defaultKeys, nil
+}
+
+func readPem(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.New("jwt | Could not read key file " + file)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt | No PEM data in " + file)
+	}
+
+	return block, nil
+}
+
+func readPrivateKey(file string) (crypto.Signer, error) {
+	block, err := readPem(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.New("jwt | Could not parse private key " + file)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("jwt | Unsupported private key " + file)
+	}
+
+	return signer, nil
+}
+
+func readPublicKey(file string) (crypto.PublicKey, error) {
+	block, err := readPem(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var key crypto.PublicKey
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.New("jwt | Could not parse public key " + file)
+	}
+
+	return key, nil
+}
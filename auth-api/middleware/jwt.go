@@ -4,19 +4,26 @@ import (
 	"net/http"
 
 	"github.com/darkcat013/cs-labs/auth-api/jwt"
+	"github.com/darkcat013/cs-labs/auth-api/services"
 	"github.com/gin-gonic/gin"
 )
 
-func JwtAuth() gin.HandlerFunc {
+// JwtAuth lets requests through with a valid access token that was not
+// revoked, every other request gets a 401.
+func JwtAuth(revocations *services.TokenRevocationService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		email, role, err := jwt.ExtractEmailAndRole(c)
+		claims, err := jwt.ExtractClaims(c)
+		if err == nil {
+			err = revocations.Check(claims)
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
-		c.Set("email", email)
-		c.Set("role", role)
+		c.Set("claims", claims)
+		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
 		c.Next()
 	}
 }
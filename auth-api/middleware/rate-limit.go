@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/darkcat013/cs-labs/auth-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit answers 429 once a client went over the limit on the route.
+func RateLimit(limiter *services.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limiter.Allow(c.FullPath() + " " + c.ClientIP()) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
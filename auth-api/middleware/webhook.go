@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/darkcat013/cs-labs/auth-api/services"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookAuth only lets through bodies that are HMAC authenticated
+// SignedMessage envelopes, the verified message is stored as "webhook".
+func WebhookAuth(webhookService *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var message domain.SignedMessage
+		if err := c.ShouldBindJSON(&message); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if err := webhookService.Verify(message); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("webhook", message)
+		c.Next()
+	}
+}
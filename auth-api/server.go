@@ -1,14 +1,19 @@
 package authapi
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/darkcat013/cs-labs/auth-api/constants"
 	"github.com/darkcat013/cs-labs/auth-api/domain"
 	"github.com/darkcat013/cs-labs/auth-api/dto"
+	jwtutil "github.com/darkcat013/cs-labs/auth-api/jwt"
 	"github.com/darkcat013/cs-labs/auth-api/middleware"
 	"github.com/darkcat013/cs-labs/auth-api/services"
 	ciphers "github.com/darkcat013/cs-labs/classic-ciphers"
+	hashdomain "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -16,11 +21,79 @@ import (
 func StartServer() error {
 	godotenv.Load()
 
-	userService := services.NewUserService()
-	otpService := services.NewOtpService()
-	mailService := services.NewMailService()
+	if _, err := jwtutil.DefaultKeys(); err != nil {
+		return err
+	}
+
+	return NewRouter().Run(":8080")
+}
+
+// Services holds the services the API handlers share.
+type Services struct {
+	Users         *services.UserService
+	Otp           *services.OtpService
+	Mail          *services.MailService
+	Webhooks      *services.WebhookService
+	RefreshTokens *services.RefreshTokenService
+	Revocations   *services.TokenRevocationService
+	// RateLimiter guards the routes that accept an OTP without a login.
+	RateLimiter *services.RateLimiter
+	// EmailRateLimiter limits issuing and checking OTPs per email, so
+	// spreading guesses over many clients does not help.
+	EmailRateLimiter *services.RateLimiter
+}
+
+// NewServices builds the API services, configured from the environment.
+func NewServices() Services {
+	return Services{
+		Users:         services.NewUserService(),
+		Otp:           services.NewOtpService(),
+		Mail:          services.NewMailService(),
+		Webhooks:      services.NewWebhookService(),
+		RefreshTokens: services.NewRefreshTokenService(),
+		Revocations:   services.NewTokenRevocationService(),
+		RateLimiter:   services.NewRateLimiter(10, time.Minute),
+
+		EmailRateLimiter: services.NewRateLimiter(10, time.Minute),
+	}
+}
+
+// NewRouter builds the API with its services, configured from the environment.
+func NewRouter() *gin.Engine {
+	return NewRouterWithServices(NewServices())
+}
+
+// NewRouterWithServices builds the API around the given services.
+func NewRouterWithServices(s Services) *gin.Engine {
+	userService := s.Users
+	otpService := s.Otp
+	mailService := s.Mail
+	webhookService := s.Webhooks
+	refreshTokenService := s.RefreshTokens
+	revocationService := s.Revocations
+	rateLimit := middleware.RateLimit(s.RateLimiter)
+
+	// allowEmail answers 429 once email went over the limit for action
+	allowEmail := func(c *gin.Context, action string, email string) bool {
+		if !s.EmailRateLimiter.Allow(action + " " + strings.ToLower(strings.TrimSpace(email))) {
+			c.JSON(429, gin.H{"error": "too many requests"})
+			return false
+		}
+		return true
+	}
 
 	ginEngine := gin.Default()
+
+	ginEngine.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		keys, err := jwtutil.DefaultKeys()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, keys.JWKS())
+	})
+
 	apiRoutes := ginEngine.Group("/api")
 
 	apiRoutes.POST("/user/register", func(c *gin.Context) {
@@ -31,6 +104,10 @@ func StartServer() error {
 			return
 		}
 
+		if !allowEmail(c, "verify", userDto.Email) {
+			return
+		}
+
 		if err := otpService.Verify(userDto.Email, userDto.Otp); err != nil {
 			c.JSON(400, gin.H{"error": err.Error()})
 			return
@@ -52,6 +129,10 @@ func StartServer() error {
 			return
 		}
 
+		if !allowEmail(c, "verify", userDto.Email) {
+			return
+		}
+
 		if err := otpService.Verify(userDto.Email, userDto.Otp); err != nil {
 			c.JSON(400, gin.H{"error": err.Error()})
 			return
@@ -63,13 +144,105 @@ func StartServer() error {
 			return
 		}
 
-		c.JSON(200, gin.H{"token": jwt})
+		refreshToken, err := refreshTokenService.Issue(userDto.Email)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"token": jwt, "refreshToken": refreshToken})
 	})
 
-	apiRoutes.POST("/otp/:email", func(c *gin.Context) {
+	apiRoutes.POST("/token/refresh", func(c *gin.Context) {
+		var tokenDto dto.RefreshTokenDto
+
+		if err := c.ShouldBindJSON(&tokenDto); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		refreshToken, email, err := refreshTokenService.Rotate(tokenDto.RefreshToken)
+		if err != nil {
+			c.JSON(401, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := userService.Get(email)
+		if err != nil {
+			refreshTokenService.RevokeUser(email)
+			c.JSON(401, gin.H{"error": err.Error()})
+			return
+		}
+
+		token, err := jwtutil.Generate(user.Email, user.Role)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"token": token, "refreshToken": refreshToken})
+	})
+
+	apiRoutes.POST("/logout", func(c *gin.Context) {
+		var tokenDto dto.RefreshTokenDto
+
+		if err := c.ShouldBindJSON(&tokenDto); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := refreshTokenService.Revoke(tokenDto.RefreshToken); err != nil {
+			c.JSON(401, gin.H{"error": err.Error()})
+			return
+		}
+
+		// the access token is optional, when sent it stops working right away
+		if claims, err := jwtutil.ExtractClaims(c); err == nil {
+			revocationService.Revoke(claims)
+		}
+
+		c.JSON(200, gin.H{"message": "Logged out."})
+	})
+
+	apiRoutes.POST("/user/password/reset", rateLimit, func(c *gin.Context) {
+		var resetDto dto.ResetPasswordDto
+
+		if err := c.ShouldBindJSON(&resetDto); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !allowEmail(c, "verify", resetDto.Email) {
+			return
+		}
+
+		if err := otpService.Verify(resetDto.Email, resetDto.Otp); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := userService.ResetPassword(resetDto.Email, resetDto.NewPassword); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		refreshTokenService.RevokeUser(resetDto.Email)
+
+		c.JSON(200, gin.H{"message": "Password reset."})
+	})
+
+	apiRoutes.POST("/otp/:email", rateLimit, func(c *gin.Context) {
 		email := c.Param("email")
 
+		if !allowEmail(c, "otp", email) {
+			return
+		}
+
 		otp, err := otpService.Generate(email)
+		if errors.Is(err, services.ErrOtpLocked) {
+			c.JSON(429, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
@@ -86,7 +259,50 @@ func StartServer() error {
 		c.JSON(200, gin.H{"message": "OTP sent to your email."})
 	})
 
-	authenticatedRoutes := apiRoutes.Use(middleware.JwtAuth())
+	apiRoutes.POST("/webhook", middleware.WebhookAuth(webhookService), func(c *gin.Context) {
+		message := c.MustGet("webhook").(hashdomain.SignedMessage)
+
+		c.JSON(200, gin.H{"sender": message.Signer, "keyId": message.KeyID})
+	})
+
+	authenticatedRoutes := apiRoutes.Use(middleware.JwtAuth(revocationService))
+
+	authenticatedRoutes.POST("/user/password", func(c *gin.Context) {
+		var changeDto dto.ChangePasswordDto
+
+		if err := c.ShouldBindJSON(&changeDto); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := userService.ChangePassword(c.GetString("email"), changeDto); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		refreshTokenService.RevokeUser(c.GetString("email"))
+
+		c.JSON(200, gin.H{"message": "Password changed."})
+	})
+
+	authenticatedRoutes.DELETE("/user", func(c *gin.Context) {
+		var deleteDto dto.DeleteAccountDto
+
+		if err := c.ShouldBindJSON(&deleteDto); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := userService.Delete(c.GetString("email"), deleteDto); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		refreshTokenService.RevokeUser(c.GetString("email"))
+		revocationService.Revoke(c.MustGet("claims").(*jwtutil.Claims))
+
+		c.JSON(204, nil)
+	})
 
 	authenticatedRoutes.POST("/caesar/encrypt", func(c *gin.Context) {
 		var caesarDto dto.CaesarDto
@@ -187,5 +403,5 @@ func StartServer() error {
 		c.JSON(200, user)
 	})
 
-	return ginEngine.Run(":8080")
+	return ginEngine
 }
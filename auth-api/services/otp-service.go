@@ -1,37 +1,101 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
 	"errors"
-	"math/rand"
-	"strconv"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
 )
 
+const (
+	// OtpTTL is how long a generated OTP can be used.
+	OtpTTL = 10 * time.Minute
+	// OtpMaxAttempts is how many wrong guesses an OTP survives.
+	OtpMaxAttempts = 5
+)
+
+// ErrOtpLocked is returned for an email that used up its wrong guesses,
+// until its last OTP expires.
+var ErrOtpLocked = errors.New("OtpService | Too many wrong guesses, try again later")
+
+type otpEntry struct {
+	otp      string
+	expires  time.Time
+	attempts int
+}
+
 type OtpService struct {
-	otpMap map[string]string
+	mu     sync.Mutex
+	otpMap map[string]otpEntry
 }
 
 func NewOtpService() *OtpService {
 
 	return &OtpService{
-		otpMap: make(map[string]string),
+		otpMap: make(map[string]otpEntry),
 	}
 }
 
 func (s *OtpService) Generate(email string) (string, error) {
-	otpNum := 100000 + rand.Intn(899999)
-	otp := strconv.Itoa(otpNum)
+	otpNum, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", errors.New("OtpService Generate | Could not generate OTP")
+	}
+	otp := fmt.Sprintf("%06d", otpNum.Int64())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry := otpEntry{otp: otp, expires: now.Add(OtpTTL)}
+
+	// a new OTP keeps the wrong guesses of the previous one, otherwise
+	// asking for another OTP would hand out a fresh set of guesses
+	if previous, ok := s.otpMap[email]; ok && now.Before(previous.expires) {
+		if previous.attempts >= OtpMaxAttempts {
+			return "", ErrOtpLocked
+		}
+		entry.attempts = previous.attempts
+	}
 
-	s.otpMap[email] = otp
+	s.otpMap[email] = entry
 
 	return otp, nil
 }
 
 func (s *OtpService) Verify(email string, otp string) error {
-	if s.otpMap[email] != otp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.otpMap[email]
+	if !ok {
+		return errors.New("OtpService Verify | OTP is not valid")
+	}
+
+	if entry.attempts >= OtpMaxAttempts {
+		if time.Now().After(entry.expires) {
+			delete(s.otpMap, email)
+			return errors.New("OtpService Verify | OTP expired")
+		}
+		return ErrOtpLocked
+	}
+
+	if subtle.ConstantTimeCompare([]byte(entry.otp), []byte(otp)) != 1 {
+		// a 6 digit OTP must not be guessable, so after a few misses the
+		// email is locked until the OTP expires
+		entry.attempts++
+		s.otpMap[email] = entry
 		return errors.New("OtpService Verify | OTP is not valid")
 	}
 
 	delete(s.otpMap, email)
 
+	if time.Now().After(entry.expires) {
+		return errors.New("OtpService Verify | OTP expired")
+	}
+
 	return nil
 }
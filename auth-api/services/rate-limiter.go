@@ -0,0 +1,51 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimiter allows a key at most Limit requests in each Window.
+type RateLimiter struct {
+	Limit  int
+	Window time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		Limit:   limit,
+		Window:  window,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+// Allow counts a request of key and reports whether it is within the limit.
+func (s *RateLimiter) Allow(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, window := range s.windows {
+		if now.Sub(window.start) >= s.Window {
+			delete(s.windows, k)
+		}
+	}
+
+	window, ok := s.windows[key]
+	if !ok {
+		window = &rateWindow{start: now}
+		s.windows[key] = window
+	}
+
+	window.count++
+
+	return window.count <= s.Limit
+}
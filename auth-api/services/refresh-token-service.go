@@ -0,0 +1,158 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RefreshTokenTTL is how long a refresh token can be exchanged.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+type refreshToken struct {
+	email   string
+	family  string
+	expires time.Time
+	used    bool
+}
+
+// RefreshTokenService issues opaque refresh tokens that are rotated on every
+// use. Tokens descending from one login form a family, presenting an already
+// used token revokes the whole family since either the user or an attacker
+// holds a stolen copy. Only hashes of the tokens are kept.
+type RefreshTokenService struct {
+	mu       sync.Mutex
+	tokens   map[string]*refreshToken
+	families map[string][]string
+}
+
+func NewRefreshTokenService() *RefreshTokenService {
+	return &RefreshTokenService{
+		tokens:   make(map[string]*refreshToken),
+		families: make(map[string][]string),
+	}
+}
+
+// Issue starts a new token family for email.
+func (s *RefreshTokenService) Issue(email string) (string, error) {
+	family, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for hash, token := range s.tokens {
+		if !now.After(token.expires) {
+			continue
+		}
+		// an expired unused token is the end of its family, the session is over
+		if !token.used {
+			s.revokeFamily(token.family)
+		}
+		delete(s.tokens, hash)
+	}
+
+	return s.issue(email, family)
+}
+
+// Rotate exchanges a refresh token for a new one of the same family and
+// returns the email it was issued to.
+func (s *RefreshTokenService) Rotate(token string) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.tokens[tokenHash(token)]
+	if !ok {
+		return "", "", errors.New("RefreshTokenService Rotate | Refresh token is not valid")
+	}
+
+	if entry.used {
+		s.revokeFamily(entry.family)
+		return "", "", errors.New("RefreshTokenService Rotate | Refresh token was already used, session revoked")
+	}
+
+	if time.Now().After(entry.expires) {
+		s.revokeFamily(entry.family)
+		return "", "", errors.New("RefreshTokenService Rotate | Refresh token expired")
+	}
+
+	entry.used = true
+
+	newToken, err := s.issue(entry.email, entry.family)
+	if err != nil {
+		return "", "", err
+	}
+
+	return newToken, entry.email, nil
+}
+
+// Revoke ends the session the refresh token belongs to.
+func (s *RefreshTokenService) Revoke(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.tokens[tokenHash(token)]
+	if !ok {
+		return errors.New("RefreshTokenService Revoke | Refresh token is not valid")
+	}
+
+	s.revokeFamily(entry.family)
+
+	return nil
+}
+
+// RevokeUser ends every session of email.
+func (s *RefreshTokenService) RevokeUser(email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.tokens {
+		if entry.email == email {
+			s.revokeFamily(entry.family)
+		}
+	}
+}
+
+func (s *RefreshTokenService) issue(email, family string) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	hash := tokenHash(token)
+	s.tokens[hash] = &refreshToken{
+		email:   email,
+		family:  family,
+		expires: time.Now().Add(RefreshTokenTTL),
+	}
+	s.families[family] = append(s.families[family], hash)
+
+	return token, nil
+}
+
+func (s *RefreshTokenService) revokeFamily(family string) {
+	for _, hash := range s.families[family] {
+		delete(s.tokens, hash)
+	}
+	delete(s.families, family)
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.New("RefreshTokenService | Could not generate token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
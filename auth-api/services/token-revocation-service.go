@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/darkcat013/cs-labs/auth-api/jwt"
+)
+
+// TokenRevocationService keeps the ids of revoked access tokens until the
+// tokens expire.
+type TokenRevocationService struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+func NewTokenRevocationService() *TokenRevocationService {
+	return &TokenRevocationService{
+		revoked: make(map[string]time.Time),
+	}
+}
+
+func (s *TokenRevocationService) Revoke(claims *jwt.Claims) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, expires := range s.revoked {
+		if now.After(expires) {
+			delete(s.revoked, id)
+		}
+	}
+
+	// keep the id past the expiry for as long as a skewed clock accepts it
+	s.revoked[claims.ID] = claims.Expiry.Time().Add(time.Hour)
+}
+
+func (s *TokenRevocationService) Check(claims *jwt.Claims) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.revoked[claims.ID]; ok {
+		return errors.New("TokenRevocationService Check | Token was revoked")
+	}
+
+	return nil
+}
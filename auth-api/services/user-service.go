@@ -3,37 +3,46 @@ package services
 import (
 	"bytes"
 	"errors"
+	"sync"
 
 	"github.com/darkcat013/cs-labs/auth-api/constants"
 	"github.com/darkcat013/cs-labs/auth-api/domain"
 	"github.com/darkcat013/cs-labs/auth-api/dto"
 	"github.com/darkcat013/cs-labs/auth-api/jwt"
-	"github.com/darkcat013/cs-labs/auth-api/utils"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/passwords"
 )
 
 type UserService struct {
-	users map[string]domain.User
+	mu     sync.RWMutex
+	users  map[string]domain.User
+	hasher *passwords.Hasher
 }
 
 func NewUserService() *UserService {
-	users := make(map[string]domain.User)
-
-	users["noroc@mail.com"] = domain.User{
-		Email:    "noroc@mail.com",
-		Password: utils.GetSHA256Digest("norocPass"),
-		Role:     constants.ROLE_ADMIN,
+	s := &UserService{
+		users:  make(map[string]domain.User),
+		hasher: passwords.NewHasher(),
 	}
 
-	users["user@mail.com"] = domain.User{
-		Email:    "user@mail.com",
-		Password: utils.GetSHA256Digest("userPass"),
-		Role:     constants.ROLE_USER,
+	s.seed("noroc@mail.com", "norocPass", constants.ROLE_ADMIN)
+	s.seed("user@mail.com", "userPass", constants.ROLE_USER)
+
+	return s
+}
+
+func (s *UserService) seed(email, password, role string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		panic(err)
 	}
 
-	return &UserService{users: users}
+	s.users[email] = domain.User{Email: email, Password: []byte(hashedPassword), Role: role}
 }
 
 func (s *UserService) Get(email string) (domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	if value, ok := s.users[email]; ok {
 		return value, nil
 	}
@@ -42,6 +51,9 @@ func (s *UserService) Get(email string) (domain.User, error) {
 }
 
 func (s *UserService) GetAll() ([]domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var users []domain.User
 
 	for _, value := range s.users {
@@ -52,13 +64,19 @@ func (s *UserService) GetAll() ([]domain.User, error) {
 }
 
 func (s *UserService) Register(dto dto.UserDto) error {
+	hashedPassword, err := s.hasher.Hash(dto.Password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if _, ok := s.users[dto.Email]; ok {
 		return errors.New("UserService Register | User already exists")
 	}
 
-	hashedPassword := utils.GetSHA256Digest(dto.Password)
-
-	s.users[dto.Email] = domain.User{Email: dto.Email, Password: hashedPassword, Role: constants.ROLE_USER}
+	s.users[dto.Email] = domain.User{Email: dto.Email, Password: []byte(hashedPassword), Role: constants.ROLE_USER}
 
 	return nil
 }
@@ -69,12 +87,26 @@ func (s *UserService) Login(dto dto.UserDto) (string, error) {
 		return "", err
 	}
 
-	hashedPassword := utils.GetSHA256Digest(dto.Password)
-
-	if !bytes.Equal(hashedPassword, user.Password) {
+	needsRehash, err := s.hasher.Verify(dto.Password, string(user.Password))
+	if err != nil {
 		return "", errors.New("UserService Login | Invalid password")
 	}
 
+	if needsRehash {
+		hashedPassword, err := s.hasher.Hash(dto.Password)
+		if err != nil {
+			return "", err
+		}
+
+		s.mu.Lock()
+		// skip the rehash if the password changed while it was being verified
+		if stored, ok := s.users[user.Email]; ok && bytes.Equal(stored.Password, user.Password) {
+			stored.Password = []byte(hashedPassword)
+			s.users[user.Email] = stored
+		}
+		s.mu.Unlock()
+	}
+
 	jwt, err := jwt.Generate(user.Email, user.Role)
 	if err != nil {
 		return "", err
@@ -82,3 +114,73 @@ func (s *UserService) Login(dto dto.UserDto) (string, error) {
 
 	return jwt, nil
 }
+
+func (s *UserService) ChangePassword(email string, dto dto.ChangePasswordDto) error {
+	user, err := s.Get(email)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.hasher.Verify(dto.OldPassword, string(user.Password)); err != nil {
+		return errors.New("UserService ChangePassword | Invalid password")
+	}
+
+	return s.setPassword(user, dto.NewPassword)
+}
+
+// ResetPassword sets a new password without the old one, the caller must have
+// verified the user's OTP first.
+func (s *UserService) ResetPassword(email, newPassword string) error {
+	user, err := s.Get(email)
+	if err != nil {
+		return err
+	}
+
+	return s.setPassword(user, newPassword)
+}
+
+func (s *UserService) Delete(email string, dto dto.DeleteAccountDto) error {
+	user, err := s.Get(email)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.hasher.Verify(dto.Password, string(user.Password)); err != nil {
+		return errors.New("UserService Delete | Invalid password")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.users[email]; !ok || !bytes.Equal(stored.Password, user.Password) {
+		return errors.New("UserService Delete | User changed meanwhile, try again")
+	}
+
+	delete(s.users, email)
+
+	return nil
+}
+
+func (s *UserService) setPassword(user domain.User, password string) error {
+	if password == "" {
+		return errors.New("UserService | Password cannot be empty")
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.users[user.Email]
+	if !ok || !bytes.Equal(stored.Password, user.Password) {
+		return errors.New("UserService | User changed meanwhile, try again")
+	}
+
+	stored.Password = []byte(hashedPassword)
+	s.users[user.Email] = stored
+
+	return nil
+}
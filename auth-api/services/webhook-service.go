@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	hashdb "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	hashdomain "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	hashservices "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+// webhookTolerance is how far the timestamp of a webhook may be from now.
+const webhookTolerance = 5 * time.Minute
+
+// WebhookService verifies HMAC authenticated webhook messages from the
+// senders configured in WEBHOOK_SECRETS, a comma separated list of
+// sender:keyId:base64secret entries.
+type WebhookService struct {
+	messages interfaces.IMessageService
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewWebhookService() *WebhookService {
+	secrets := hashdb.NewSecretStore()
+
+	for _, entry := range strings.Split(os.Getenv("WEBHOOK_SECRETS"), ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			continue
+		}
+
+		secret, err := base64.StdEncoding.DecodeString(parts[2])
+		if err != nil {
+			continue
+		}
+		secrets.Add(parts[0], parts[1], secret)
+	}
+
+	return &WebhookService{
+		messages: hashservices.NewHmacMessageService(secrets),
+		seen:     make(map[string]time.Time),
+	}
+}
+
+// Verify accepts a message once, if its MAC is valid and it is recent.
+func (s *WebhookService) Verify(message hashdomain.SignedMessage) error {
+	if err := s.messages.CheckMessage(nil, message); err != nil {
+		return errors.New("WebhookService Verify | Invalid message authentication")
+	}
+
+	now := time.Now()
+	if message.Timestamp.Before(now.Add(-webhookTolerance)) || message.Timestamp.After(now.Add(webhookTolerance)) {
+		return errors.New("WebhookService Verify | Message timestamp is outside the allowed window")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for mac, expires := range s.seen {
+		if now.After(expires) {
+			delete(s.seen, mac)
+		}
+	}
+
+	mac := hex.EncodeToString(message.Signature)
+	if _, ok := s.seen[mac]; ok {
+		return errors.New("WebhookService Verify | Message was already received")
+	}
+	s.seen[mac] = message.Timestamp.Add(webhookTolerance)
+
+	return nil
+}
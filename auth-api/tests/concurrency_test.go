@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/auth-api/dto"
+	"github.com/darkcat013/cs-labs/auth-api/services"
+)
+
+// Run with go test -race to catch unsynchronized access.
+
+func TestConcurrentOtp(t *testing.T) {
+	//Arrange
+	otpService := services.NewOtpService()
+	var wg sync.WaitGroup
+	errs := make(chan error, 256)
+
+	//Act
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			email := fmt.Sprintf("user%d@mail.com", g)
+			for i := 0; i < 10; i++ {
+				otp, _ := otpService.Generate(email)
+				if err := otpService.Verify(email, otp); err != nil {
+					errs <- err
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	//Assert
+	for err := range errs {
+		t.Errorf("Expected no errors, got %s", err.Error())
+	}
+}
+
+func TestConcurrentOtpIsSingleUse(t *testing.T) {
+	//Arrange
+	otpService := services.NewOtpService()
+	otp, _ := otpService.Generate("user@mail.com")
+	var verified int32
+	var wg sync.WaitGroup
+
+	//Act
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if otpService.Verify("user@mail.com", otp) == nil {
+				atomic.AddInt32(&verified, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	//Assert
+	if verified != 1 {
+		t.Fatalf("Expected the OTP to verify exactly once, got %d", verified)
+	}
+}
+
+func TestConcurrentRegisterAndLogin(t *testing.T) {
+	//Arrange
+	t.Setenv("SECRET", "test secret")
+	userService := services.NewUserService()
+	var registered int32
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+
+	//Act
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			// two goroutines race for each email
+			userDto := dto.UserDto{Email: fmt.Sprintf("user%d@mail.com", g/2), Password: "pass"}
+			if userService.Register(userDto) == nil {
+				atomic.AddInt32(&registered, 1)
+			}
+			if _, err := userService.Login(userDto); err != nil {
+				errs <- err
+			}
+			userService.GetAll()
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	//Assert
+	if registered != 4 {
+		t.Errorf("Expected 4 successful registrations, got %d", registered)
+	}
+	for err := range errs {
+		t.Errorf("Expected no errors, got %s", err.Error())
+	}
+}
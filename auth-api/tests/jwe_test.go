@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEncryptedJwt(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for alg, encryptionKey := range map[string]crypto.Signer{"RSA-OAEP-256": rsaKey, "ECDH-ES+A256KW": ecKey} {
+		//Arrange
+		router, s := newAccountRouter(t)
+		encryptionFile, _ := writeKeyPair(t, encryptionKey)
+		t.Setenv("JWT_ENCRYPTION_KEY_FILE", encryptionFile)
+
+		//Act
+		_, token, _ := login(t, router, s, "user@mail.com", "userPass")
+		code, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+		header := tokenHeader(token)
+
+		//Assert
+		if len(strings.Split(token, ".")) != 5 || header["alg"] != alg || header["enc"] != "A256GCM" {
+			t.Errorf("%s: Expected a JWE with A256GCM, got header %v", alg, header)
+		}
+		if strings.Contains(token, "user@mail.com") {
+			t.Errorf("%s: Email should not be readable from the token", alg)
+		}
+		if code != http.StatusOK {
+			t.Errorf("%s: Expected status 200, got %d", alg, code)
+		}
+	}
+}
+
+func TestEncryptedJwtWithSigningKey(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	signers := newSigners(t)
+	privateFile, _ := writeKeyPair(t, signers["EdDSA"])
+	encryptionFile, _ := writeKeyPair(t, signers["RS256"])
+	t.Setenv("JWT_PRIVATE_KEY_FILE", privateFile)
+	t.Setenv("JWT_ENCRYPTION_KEY_FILE", encryptionFile)
+	_, token, _ := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	code, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+	t.Setenv("JWT_ENCRYPTION_KEY_FILE", "")
+	withoutKeyCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+
+	//Assert
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if withoutKeyCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without the encryption key, got %d", withoutKeyCode)
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jwtutil "github.com/darkcat013/cs-labs/auth-api/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v3"
+)
+
+func writeKeyPair(t *testing.T, signer crypto.Signer) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	privateDer, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateFile := filepath.Join(dir, "private.pem")
+	publicFile := filepath.Join(dir, "public.pem")
+	os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer}), 0600)
+	os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer}), 0644)
+
+	return privateFile, publicFile
+}
+
+func newSigners(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	return map[string]crypto.Signer{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey}
+}
+
+func tokenHeader(token string) map[string]interface{} {
+	var header map[string]interface{}
+	data, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	json.Unmarshal(data, &header)
+	return header
+}
+
+func fetchJwks(router *gin.Engine) (int, jose.JSONWebKeySet) {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	var set jose.JSONWebKeySet
+	json.Unmarshal(recorder.Body.Bytes(), &set)
+	return recorder.Code, set
+}
+
+func TestAsymmetricJwt(t *testing.T) {
+	for alg, signer := range newSigners(t) {
+		//Arrange
+		router, s := newAccountRouter(t)
+		privateFile, _ := writeKeyPair(t, signer)
+		t.Setenv("JWT_PRIVATE_KEY_FILE", privateFile)
+		kid, _ := jwtutil.Thumbprint(signer.Public())
+
+		//Act
+		_, token, _ := login(t, router, s, "user@mail.com", "userPass")
+		code, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+		jwksCode, set := fetchJwks(router)
+		header := tokenHeader(token)
+
+		//Assert
+		if header["alg"] != alg || header["kid"] != kid {
+			t.Errorf("%s: Expected alg %s and kid %s, got %v and %v", alg, alg, kid, header["alg"], header["kid"])
+		}
+		if code != http.StatusOK {
+			t.Errorf("%s: Expected status 200, got %d", alg, code)
+		}
+		if jwksCode != http.StatusOK || len(set.Keys) != 1 || set.Keys[0].KeyID != kid || set.Keys[0].Algorithm != alg {
+			t.Errorf("%s: Expected the signing key in the JWKS, got %+v", alg, set.Keys)
+		}
+	}
+}
+
+func TestJwtKeyRotation(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	signers := newSigners(t)
+	oldPrivate, oldPublic := writeKeyPair(t, signers["RS256"])
+	newPrivate, _ := writeKeyPair(t, signers["EdDSA"])
+
+	t.Setenv("JWT_PRIVATE_KEY_FILE", oldPrivate)
+	_, oldToken, _ := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	t.Setenv("JWT_PRIVATE_KEY_FILE", newPrivate)
+	t.Setenv("JWT_PUBLIC_KEY_FILES", oldPublic)
+	rotatedCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", oldToken, gin.H{"text": "abc", "key": 3})
+	_, set := fetchJwks(router)
+
+	t.Setenv("JWT_PUBLIC_KEY_FILES", "")
+	retiredCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", oldToken, gin.H{"text": "abc", "key": 3})
+
+	//Assert
+	if rotatedCode != http.StatusOK {
+		t.Errorf("Expected tokens of the previous key to be accepted, got %d", rotatedCode)
+	}
+	if len(set.Keys) != 2 {
+		t.Errorf("Expected both keys in the JWKS, got %d", len(set.Keys))
+	}
+	if retiredCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a retired key, got %d", retiredCode)
+	}
+}
+
+func TestHmacTokenRejectedWithPublicKeys(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, hmacToken, _ := login(t, router, s, "user@mail.com", "userPass")
+	privateFile, _ := writeKeyPair(t, newSigners(t)["ES256"])
+	t.Setenv("JWT_PRIVATE_KEY_FILE", privateFile)
+
+	//Act
+	code, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", hmacToken, gin.H{"text": "abc", "key": 3})
+
+	//Assert
+	if code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for an HS256 token, got %d", code)
+	}
+}
+
+func TestMissingSecret(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	t.Setenv("SECRET", "")
+
+	//Act
+	code, token, _ := login(t, router, s, "user@mail.com", "userPass")
+	_, set := fetchJwks(router)
+
+	//Assert
+	if code == http.StatusOK || token != "" {
+		t.Errorf("Login should fail without a SECRET, got %d", code)
+	}
+	if len(set.Keys) != 0 {
+		t.Errorf("Expected no published keys, got %d", len(set.Keys))
+	}
+}
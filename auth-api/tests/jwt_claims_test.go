@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	jwtutil "github.com/darkcat013/cs-labs/auth-api/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+func validClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":   jwtutil.DefaultIssuer,
+		"sub":   "user@mail.com",
+		"aud":   jwtutil.DefaultAudience,
+		"exp":   now.Add(time.Minute).Unix(),
+		"nbf":   now.Unix(),
+		"iat":   now.Unix(),
+		"jti":   "token-id",
+		"email": "user@mail.com",
+		"role":  "USER",
+	}
+}
+
+func signClaims(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	keys, err := jwtutil.NewHmacKeys([]byte("test secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := keys.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJwtClaimsValidation(t *testing.T) {
+	router, _ := newAccountRouter(t)
+	now := time.Now()
+
+	cases := map[string]func(map[string]interface{}){
+		"wrong issuer":        func(c map[string]interface{}) { c["iss"] = "someone else" },
+		"wrong audience":      func(c map[string]interface{}) { c["aud"] = []string{"other", "api"} },
+		"expired":             func(c map[string]interface{}) { c["exp"] = now.Add(-time.Minute).Unix() },
+		"no expiration":       func(c map[string]interface{}) { delete(c, "exp") },
+		"not valid yet":       func(c map[string]interface{}) { c["nbf"] = now.Add(time.Minute).Unix() },
+		"issued in future":    func(c map[string]interface{}) { c["iat"] = now.Add(time.Minute).Unix() },
+		"no token id":         func(c map[string]interface{}) { delete(c, "jti") },
+		"email is not string": func(c map[string]interface{}) { c["email"] = 42 },
+		"no role":             func(c map[string]interface{}) { delete(c, "role") },
+		"subject mismatch":    func(c map[string]interface{}) { c["sub"] = "noroc@mail.com" },
+	}
+
+	for name, change := range cases {
+		//Arrange
+		claims := validClaims()
+		change(claims)
+
+		//Act
+		code, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", signClaims(t, claims), gin.H{"text": "abc", "key": 3})
+
+		//Assert
+		if code != http.StatusUnauthorized {
+			t.Errorf("%s: Expected status 401, got %d", name, code)
+		}
+	}
+}
+
+func TestJwtClockSkew(t *testing.T) {
+	//Arrange
+	router, _ := newAccountRouter(t)
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+	claims["aud"] = []string{"other", jwtutil.DefaultAudience}
+	token := signClaims(t, claims)
+
+	//Act
+	skewedCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+	t.Setenv("JWT_CLOCK_SKEW", "0s")
+	strictCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+
+	//Assert
+	if skewedCode != http.StatusOK {
+		t.Errorf("Expected status 200 within the clock skew, got %d", skewedCode)
+	}
+	if strictCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without clock skew, got %d", strictCode)
+	}
+}
+
+func TestMalformedJwt(t *testing.T) {
+	router, _ := newAccountRouter(t)
+	payload, _ := json.Marshal(validClaims())
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+
+	for name, token := range map[string]string{"missing": "", "garbage": "not.a.token", "unsigned": unsigned} {
+		//Act
+		code, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+
+		//Assert
+		if code != http.StatusUnauthorized {
+			t.Errorf("%s: Expected status 401, got %d", name, code)
+		}
+	}
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, token, refreshToken := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	beforeCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+	sendJson(router, http.MethodPost, "/api/logout", token, gin.H{"refreshToken": refreshToken})
+	afterCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+
+	//Assert
+	if beforeCode != http.StatusOK {
+		t.Errorf("Expected status 200 before logout, got %d", beforeCode)
+	}
+	if afterCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 after logout, got %d", afterCode)
+	}
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	authapi "github.com/darkcat013/cs-labs/auth-api"
+	"github.com/darkcat013/cs-labs/auth-api/services"
+	"github.com/gin-gonic/gin"
+)
+
+func newAccountRouter(t *testing.T) (*gin.Engine, authapi.Services) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("SECRET", "test secret")
+	s := authapi.NewServices()
+	return authapi.NewRouterWithServices(s), s
+}
+
+func sendJson(router *gin.Engine, method, path, token string, body interface{}) (int, map[string]interface{}) {
+	payload, _ := json.Marshal(body)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(recorder, request)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	return recorder.Code, response
+}
+
+// login returns the status code with the access and refresh tokens.
+func login(t *testing.T, router *gin.Engine, s authapi.Services, email, password string) (int, string, string) {
+	t.Helper()
+	otp, err := s.Otp.Generate(email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, response := sendJson(router, http.MethodPost, "/api/user/login", "", gin.H{"email": email, "password": password, "otp": otp})
+	token, _ := response["token"].(string)
+	refreshToken, _ := response["refreshToken"].(string)
+	return code, token, refreshToken
+}
+
+func TestChangePasswordEndpoint(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, token, _ := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	wrongCode, _ := sendJson(router, http.MethodPost, "/api/user/password", token, gin.H{"oldPassword": "wrong", "newPassword": "newPass"})
+	anonymousCode, _ := sendJson(router, http.MethodPost, "/api/user/password", "", gin.H{"oldPassword": "userPass", "newPassword": "newPass"})
+	code, _ := sendJson(router, http.MethodPost, "/api/user/password", token, gin.H{"oldPassword": "userPass", "newPassword": "newPass"})
+	oldCode, _, _ := login(t, router, s, "user@mail.com", "userPass")
+	newCode, _, _ := login(t, router, s, "user@mail.com", "newPass")
+
+	//Assert
+	if wrongCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a wrong password, got %d", wrongCode)
+	}
+	if anonymousCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a token, got %d", anonymousCode)
+	}
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if oldCode != http.StatusBadRequest || newCode != http.StatusOK {
+		t.Errorf("Expected login to fail with the old password and work with the new one, got %d and %d", oldCode, newCode)
+	}
+}
+
+func TestResetPasswordEndpoint(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	otp, _ := s.Otp.Generate("user@mail.com")
+
+	//Act
+	noOtpCode, _ := sendJson(router, http.MethodPost, "/api/user/password/reset", "", gin.H{"email": "noroc@mail.com", "otp": "", "newPassword": "newPass"})
+	code, _ := sendJson(router, http.MethodPost, "/api/user/password/reset", "", gin.H{"email": "user@mail.com", "otp": otp, "newPassword": "newPass"})
+	reuseCode, _ := sendJson(router, http.MethodPost, "/api/user/password/reset", "", gin.H{"email": "user@mail.com", "otp": otp, "newPassword": "otherPass"})
+	loginCode, _, _ := login(t, router, s, "user@mail.com", "newPass")
+
+	//Assert
+	if noOtpCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a requested OTP, got %d", noOtpCode)
+	}
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if reuseCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a used OTP, got %d", reuseCode)
+	}
+	if loginCode != http.StatusOK {
+		t.Errorf("Expected login with the reset password to work, got %d", loginCode)
+	}
+}
+
+func TestDeleteAccountEndpoint(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, token, _ := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	wrongCode, _ := sendJson(router, http.MethodDelete, "/api/user", token, gin.H{"password": "wrong"})
+	code, _ := sendJson(router, http.MethodDelete, "/api/user", token, gin.H{"password": "userPass"})
+	_, getErr := s.Users.Get("user@mail.com")
+
+	//Assert
+	if wrongCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a wrong password, got %d", wrongCode)
+	}
+	if code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if getErr == nil {
+		t.Errorf("Deleted user should not be found")
+	}
+}
+
+func TestResetPasswordRefusedAfterWrongGuesses(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	otp, _ := s.Otp.Generate("user@mail.com")
+	wrong := "000000"
+	if otp == wrong {
+		wrong = "111111"
+	}
+
+	//Act
+	for i := 0; i < services.OtpMaxAttempts; i++ {
+		sendJson(router, http.MethodPost, "/api/user/password/reset", "", gin.H{"email": "user@mail.com", "otp": wrong, "newPassword": "newPass"})
+	}
+	code, _ := sendJson(router, http.MethodPost, "/api/user/password/reset", "", gin.H{"email": "user@mail.com", "otp": otp, "newPassword": "newPass"})
+
+	//Assert
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for the right OTP after %d wrong guesses, got %d", services.OtpMaxAttempts, code)
+	}
+}
+
+func TestResetPasswordRateLimited(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	codes := map[int]int{}
+
+	//Act
+	for i := 0; i <= s.RateLimiter.Limit; i++ {
+		code, _ := sendJson(router, http.MethodPost, "/api/user/password/reset", "", gin.H{"email": "user@mail.com", "otp": "000000", "newPassword": "newPass"})
+		codes[code]++
+	}
+
+	//Assert
+	if codes[http.StatusTooManyRequests] != 1 {
+		t.Errorf("Expected one request over the limit to get status 429, got %v", codes)
+	}
+}
+
+func TestNewOtpKeepsWrongGuesses(t *testing.T) {
+	//Arrange
+	otpService := services.NewOtpService()
+	otp, _ := otpService.Generate("user@mail.com")
+	wrong := "000000"
+	if otp == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < services.OtpMaxAttempts; i++ {
+		otpService.Verify("user@mail.com", wrong)
+	}
+
+	//Act
+	_, generateErr := otpService.Generate("user@mail.com")
+	verifyErr := otpService.Verify("user@mail.com", otp)
+
+	//Assert
+	if generateErr != services.ErrOtpLocked {
+		t.Errorf("Expected a new OTP to be refused after %d wrong guesses, got %v", services.OtpMaxAttempts, generateErr)
+	}
+	if verifyErr != services.ErrOtpLocked {
+		t.Errorf("Expected the right OTP to be refused after %d wrong guesses, got %v", services.OtpMaxAttempts, verifyErr)
+	}
+}
+
+func TestOtpRateLimitedPerEmail(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	codes := map[int]int{}
+
+	//Act
+	for i := 0; i <= s.EmailRateLimiter.Limit; i++ {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/api/otp/user@mail.com", nil)
+		request.Header.Set("X-Forwarded-For", "10.0.0."+strconv.Itoa(i+1))
+		router.ServeHTTP(recorder, request)
+		codes[recorder.Code]++
+	}
+
+	//Assert
+	if codes[http.StatusTooManyRequests] != 1 {
+		t.Errorf("Expected one request over the email limit to get status 429, got %v", codes)
+	}
+}
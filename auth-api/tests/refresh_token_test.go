@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func refresh(router *gin.Engine, refreshToken string) (int, string, string) {
+	code, response := sendJson(router, http.MethodPost, "/api/token/refresh", "", gin.H{"refreshToken": refreshToken})
+	token, _ := response["token"].(string)
+	newRefreshToken, _ := response["refreshToken"].(string)
+	return code, token, newRefreshToken
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, _, refreshToken := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	code, token, rotated := refresh(router, refreshToken)
+	secondCode, _, _ := refresh(router, rotated)
+	accessCode, _ := sendJson(router, http.MethodPost, "/api/caesar/encrypt", token, gin.H{"text": "abc", "key": 3})
+
+	//Assert
+	if code != http.StatusOK || secondCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for both refreshes, got %d and %d", code, secondCode)
+	}
+	if rotated == "" || rotated == refreshToken {
+		t.Errorf("Refresh token should be rotated")
+	}
+	if accessCode != http.StatusOK {
+		t.Errorf("Expected the refreshed access token to work, got %d", accessCode)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, _, refreshToken := login(t, router, s, "user@mail.com", "userPass")
+	_, _, otherSession := login(t, router, s, "user@mail.com", "userPass")
+	_, _, rotated := refresh(router, refreshToken)
+
+	//Act
+	reuseCode, _, _ := refresh(router, refreshToken)
+	rotatedCode, _, _ := refresh(router, rotated)
+	otherCode, _, _ := refresh(router, otherSession)
+
+	//Assert
+	if reuseCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a reused refresh token, got %d", reuseCode)
+	}
+	if rotatedCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a token of a revoked family, got %d", rotatedCode)
+	}
+	if otherCode != http.StatusOK {
+		t.Errorf("Expected other sessions to survive, got %d", otherCode)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, _, refreshToken := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	code, _ := sendJson(router, http.MethodPost, "/api/logout", "", gin.H{"refreshToken": refreshToken})
+	refreshCode, _, _ := refresh(router, refreshToken)
+
+	//Assert
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if refreshCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 after logout, got %d", refreshCode)
+	}
+}
+
+func TestPasswordChangeRevokesSessions(t *testing.T) {
+	//Arrange
+	router, s := newAccountRouter(t)
+	_, token, refreshToken := login(t, router, s, "user@mail.com", "userPass")
+
+	//Act
+	sendJson(router, http.MethodPost, "/api/user/password", token, gin.H{"oldPassword": "userPass", "newPassword": "newPass"})
+	code, _, _ := refresh(router, refreshToken)
+
+	//Assert
+	if code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 after a password change, got %d", code)
+	}
+}
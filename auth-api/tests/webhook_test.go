@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authapi "github.com/darkcat013/cs-labs/auth-api"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+	"github.com/gin-gonic/gin"
+)
+
+var webhookSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func newWebhookRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("WEBHOOK_SECRETS", "billing:k1:"+base64.StdEncoding.EncodeToString(webhookSecret))
+	return authapi.NewRouter()
+}
+
+func newWebhookMessage(t *testing.T, secret []byte) domain.SignedMessage {
+	t.Helper()
+	secrets := database.NewSecretStore()
+	secrets.Add("billing", "k1", secret)
+
+	message, err := services.NewHmacMessageService(secrets).NewMessage(domain.User{Username: "billing"}, `{"invoice":42}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func postWebhook(router *gin.Engine, message domain.SignedMessage) int {
+	body, _ := json.Marshal(message)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/webhook", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestWebhookAccepted(t *testing.T) {
+	//Arrange
+	router := newWebhookRouter(t)
+	message := newWebhookMessage(t, webhookSecret)
+
+	//Act
+	code := postWebhook(router, message)
+	replayCode := postWebhook(router, message)
+
+	//Assert
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if replayCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a replayed message, got %d", replayCode)
+	}
+}
+
+func TestWebhookRejected(t *testing.T) {
+	//Arrange
+	router := newWebhookRouter(t)
+	forged := newWebhookMessage(t, []byte("wrong secret"))
+	altered := newWebhookMessage(t, webhookSecret)
+	altered.Payload = []byte(`{"invoice":43}`)
+
+	//Act
+	forgedCode := postWebhook(router, forged)
+	alteredCode := postWebhook(router, altered)
+
+	//Assert
+	if forgedCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a forged message, got %d", forgedCode)
+	}
+	if alteredCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for an altered message, got %d", alteredCode)
+	}
+}
@@ -0,0 +1,356 @@
+// Command signer creates and checks detached signatures of files with the
+// keys of hash-func-and-digital-sign users.
+//
+//	signer register --user alice --password secret
+//	signer sign --user alice --password secret file.tar
+//	signer verify --sig file.tar.sig file.tar
+//	signer rotate --user alice --password secret
+//	signer revoke --user alice --password secret --key KEYID --reason keyCompromise
+//
+// Users and their keys live in the JSON database given by --db. If
+// SIGNER_MASTER_KEYS or SIGNER_MASTER_KEY_FILE holds master keys, as
+// id:base64key entries with the current one first, private keys are stored
+// encrypted with them.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/keystore"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+const usage = `usage:
+  signer register --user NAME [--password PASS] [--key-algorithm ALG] [--db FILE]
+  signer sign --user NAME [--password PASS] [--hash ALG] [--out FILE.sig] [--db FILE] FILE
+  signer verify [--sig FILE.sig] [--db FILE] FILE
+  signer rotate --user NAME [--password PASS] [--key-algorithm ALG] [--db FILE]
+  signer revoke --user NAME [--password PASS] --key KEYID [--reason REASON] [--db FILE]
+  signer revocations [--db FILE]
+  signer rewrap [--db FILE]
+
+The password defaults to the SIGNER_PASSWORD environment variable.
+Private keys are encrypted with the master keys in SIGNER_MASTER_KEYS or
+SIGNER_MASTER_KEY_FILE, rewrap re-encrypts them with the first one.`
+
+const keyAlgorithmUsage = "signing key algorithm: " +
+	domain.KeyRSA2048 + ", " + domain.KeyRSA3072 + ", " + domain.KeyRSA4096 + ", " +
+	domain.KeyECDSAP256 + " or " + domain.KeyECDSAP384
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "register":
+		err = register(os.Args[2:])
+	case "sign":
+		err = sign(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	case "rotate":
+		err = rotate(os.Args[2:])
+	case "revoke":
+		err = revoke(os.Args[2:])
+	case "revocations":
+		err = revocations(os.Args[2:])
+	case "rewrap":
+		err = rewrap(os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	// the commands never generate from the shared pool, this only makes sure
+	// no workers outlive them
+	services.CloseDefaultKeyPool()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "signer:", err)
+		os.Exit(1)
+	}
+}
+
+func register(args []string) error {
+	flags := flag.NewFlagSet("register", flag.ExitOnError)
+	dbPath := flags.String("db", "users.json", "user database file")
+	user := flags.String("user", "", "username")
+	password := flags.String("password", os.Getenv("SIGNER_PASSWORD"), "password")
+	keyAlgorithm := flags.String("key-algorithm", services.DefaultKeyAlgorithm, keyAlgorithmUsage)
+	flags.Parse(args)
+
+	if *user == "" || *password == "" {
+		return errors.New("--user and --password are required")
+	}
+
+	db, err := openDatabase(*dbPath)
+	if err != nil {
+		return err
+	}
+
+	keys, err := services.NewKeyGenerator(*keyAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	if err := services.NewUserServiceWithKeys(db, keys).Register(*user, *password); err != nil {
+		return err
+	}
+
+	fmt.Printf("registered %s\n", *user)
+	return nil
+}
+
+func sign(args []string) error {
+	flags := flag.NewFlagSet("sign", flag.ExitOnError)
+	dbPath := flags.String("db", "users.json", "user database file")
+	user := flags.String("user", "", "username of the signer")
+	password := flags.String("password", os.Getenv("SIGNER_PASSWORD"), "password of the signer")
+	out := flags.String("out", "", "signature file, defaults to FILE.sig")
+	hashAlgorithm := flags.String("hash", domain.HashSHA256, "hash algorithm, one of "+strings.Join(utils.HashNames(), ", "))
+	flags.Parse(args)
+
+	if *user == "" || flags.NArg() != 1 {
+		return errors.New("--user and exactly one FILE are required")
+	}
+	path := flags.Arg(0)
+	if *out == "" {
+		*out = path + ".sig"
+	}
+
+	db, err := openDatabase(*dbPath)
+	if err != nil {
+		return err
+	}
+
+	signer, err := services.NewUserService(db).Login(*user, *password)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fileService, err := services.NewFileSignatureServiceWithHash(*hashAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	signature, err := fileService.Sign(signer, file)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(signature, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("signed %s as %s, signature written to %s\n", path, *user, *out)
+	return nil
+}
+
+func verify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	dbPath := flags.String("db", "users.json", "user database file")
+	sigPath := flags.String("sig", "", "signature file, defaults to FILE.sig")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("exactly one FILE is required")
+	}
+	path := flags.Arg(0)
+	if *sigPath == "" {
+		*sigPath = path + ".sig"
+	}
+
+	data, err := os.ReadFile(*sigPath)
+	if err != nil {
+		return err
+	}
+
+	var signature domain.DetachedSignature
+	if err := json.Unmarshal(data, &signature); err != nil {
+		return err
+	}
+
+	db, err := openDatabase(*dbPath)
+	if err != nil {
+		return err
+	}
+
+	if err := check(db, path, signature); err != nil {
+		return err
+	}
+
+	fmt.Printf("good signature on %s by %s (key %s)\n", path, signature.Signer, signature.KeyID)
+	return nil
+}
+
+func check(db interfaces.IDatabase, path string, signature domain.DetachedSignature) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	directory := services.NewKeyDirectoryService(db, nil)
+	return services.CheckPublishedFile(services.NewFileSignatureService(), directory, file, signature)
+}
+
+func rotate(args []string) error {
+	flags := flag.NewFlagSet("rotate", flag.ExitOnError)
+	dbPath := flags.String("db", "users.json", "user database file")
+	user := flags.String("user", "", "username")
+	password := flags.String("password", os.Getenv("SIGNER_PASSWORD"), "password")
+	keyAlgorithm := flags.String("key-algorithm", services.DefaultKeyAlgorithm, keyAlgorithmUsage)
+	flags.Parse(args)
+
+	keys, err := services.NewKeyGenerator(*keyAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	db, err := login(*dbPath, *user, *password)
+	if err != nil {
+		return err
+	}
+
+	key, err := services.NewKeyServiceWithKeys(db, keys).Rotate(*user)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("new active key of %s is %s\n", *user, key.ID)
+	return nil
+}
+
+func revoke(args []string) error {
+	flags := flag.NewFlagSet("revoke", flag.ExitOnError)
+	dbPath := flags.String("db", "users.json", "user database file")
+	user := flags.String("user", "", "username")
+	password := flags.String("password", os.Getenv("SIGNER_PASSWORD"), "password")
+	keyID := flags.String("key", "", "id of the key to revoke")
+	reason := flags.String("reason", string(domain.ReasonUnspecified), "revocation reason")
+	flags.Parse(args)
+
+	if *keyID == "" {
+		return errors.New("--key is required")
+	}
+
+	db, err := login(*dbPath, *user, *password)
+	if err != nil {
+		return err
+	}
+
+	if err := services.NewKeyService(db).Revoke(*user, *keyID, domain.RevocationReason(*reason)); err != nil {
+		return err
+	}
+
+	fmt.Printf("revoked key %s of %s\n", *keyID, *user)
+	return nil
+}
+
+func revocations(args []string) error {
+	flags := flag.NewFlagSet("revocations", flag.ExitOnError)
+	dbPath := flags.String("db", "users.json", "user database file")
+	flags.Parse(args)
+
+	db, err := openDatabase(*dbPath)
+	if err != nil {
+		return err
+	}
+
+	list, err := services.NewKeyService(db).RevocationList()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+func rewrap(args []string) error {
+	flags := flag.NewFlagSet("rewrap", flag.ExitOnError)
+	dbPath := flags.String("db", "users.json", "user database file")
+	flags.Parse(args)
+
+	keyring, err := loadKeyring()
+	if err != nil {
+		return err
+	}
+	if keyring == nil {
+		return errors.New("SIGNER_MASTER_KEYS or SIGNER_MASTER_KEY_FILE is required")
+	}
+
+	db, err := openDatabase(*dbPath)
+	if err != nil {
+		return err
+	}
+
+	count, err := services.RewrapKeys(db, keyring)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("rewrapped the keys of %d users with master key %s\n", count, keyring.Current())
+	return nil
+}
+
+func openDatabase(path string) (interfaces.IDatabase, error) {
+	keyring, err := loadKeyring()
+	if err != nil {
+		return nil, err
+	}
+	return database.NewEncryptedFileDatabase(path, keyring)
+}
+
+// loadKeyring returns nil if no master keys are configured.
+func loadKeyring() (*keystore.Keyring, error) {
+	if path := os.Getenv("SIGNER_MASTER_KEY_FILE"); path != "" {
+		return keystore.LoadKeyringFile(path)
+	}
+	if os.Getenv("SIGNER_MASTER_KEYS") != "" {
+		return keystore.LoadKeyringEnv("SIGNER_MASTER_KEYS")
+	}
+	return nil, nil
+}
+
+// login opens the database and checks the user's password.
+func login(dbPath, user, password string) (interfaces.IDatabase, error) {
+	if user == "" {
+		return nil, errors.New("--user is required")
+	}
+
+	db, err := openDatabase(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := services.NewUserService(db).Login(user, password); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/keystore"
+)
+
+// FileDatabase keeps all users in memory and persists them to a single JSON
+// file. Every write replaces the file atomically, so a crash leaves either the
+// old or the new contents on disk, never a partial file.
+type FileDatabase struct {
+	path    string
+	keyring *keystore.Keyring
+	mu      sync.RWMutex
+	users   map[string]domain.User
+}
+
+// NewFileDatabase opens the database stored at path, creating it on the
+// first write if it does not exist yet. Private keys are stored as they are
+// given. It has no keyring to unseal sealed keys, so sealed keys read back
+// can verify signatures but cannot sign.
+func NewFileDatabase(path string) (interfaces.IDatabase, error) {
+	return NewEncryptedFileDatabase(path, nil)
+}
+
+// NewEncryptedFileDatabase seals every private key written with keyring
+// before it is kept in memory or on disk.
+func NewEncryptedFileDatabase(path string, keyring *keystore.Keyring) (interfaces.IDatabase, error) {
+	db := &FileDatabase{path: path, keyring: keyring, users: make(map[string]domain.User)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records map[string]userRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+
+	for username, record := range records {
+		user, err := fromRecord(record, keyring)
+		if err != nil {
+			return nil, err
+		}
+		db.users[username] = user
+	}
+
+	return db, nil
+}
+
+func (db *FileDatabase) Get(username string) (domain.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return getUser(db.users, username)
+}
+
+func (db *FileDatabase) Set(username string, value domain.User) error {
+	return db.WithTx(func(tx interfaces.ITransaction) error {
+		return tx.Set(username, value)
+	})
+}
+
+func (db *FileDatabase) Delete(username string) error {
+	return db.WithTx(func(tx interfaces.ITransaction) error {
+		return tx.Delete(username)
+	})
+}
+
+func (db *FileDatabase) List(filter domain.UserFilter, page domain.Pagination) ([]domain.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return listUsers(db.users, filter, page)
+}
+
+func (db *FileDatabase) CompareAndSet(username string, value domain.User) error {
+	return db.WithTx(func(tx interfaces.ITransaction) error {
+		return tx.CompareAndSet(username, value)
+	})
+}
+
+// WithTx commits by applying the changes in memory and flushing the file,
+// the in-memory changes are undone if the flush fails.
+func (db *FileDatabase) WithTx(fn func(tx interfaces.ITransaction) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := newTransaction(db.users)
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if db.keyring != nil {
+		if err := tx.sealKeys(db.keyring); err != nil {
+			return err
+		}
+	}
+
+	previous := tx.apply()
+	if err := db.flush(); err != nil {
+		undo(db.users, previous)
+		return err
+	}
+
+	return nil
+}
+
+// flush writes the users to a temporary file in the same directory, syncs
+// it and renames it over the database file.
+func (db *FileDatabase) flush() error {
+	records := make(map[string]userRecord, len(db.users))
+	for username, user := range db.users {
+		record, err := toRecord(user)
+		if err != nil {
+			return err
+		}
+		records[username] = record
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(db.path), filepath.Base(db.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), db.path)
+}
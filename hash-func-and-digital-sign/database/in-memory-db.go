@@ -1,13 +1,14 @@
 package database
 
 import (
-	"errors"
+	"sync"
 
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
 )
 
 type InMemoryDatabase struct {
+	mu    sync.RWMutex
 	Users map[string]domain.User
 }
 
@@ -16,24 +17,50 @@ func NewDatabase() interfaces.IDatabase {
 }
 
 func (db *InMemoryDatabase) Get(username string) (domain.User, error) {
-	if user, ok := db.Users[username]; ok {
-		return user, nil
-	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
-	return domain.User{}, errors.New("database get | user not found")
+	return getUser(db.Users, username)
 }
 
 func (db *InMemoryDatabase) Set(username string, value domain.User) error {
-	db.Users[username] = value
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	setUser(db.Users, username, value)
 	return nil
 }
 
 func (db *InMemoryDatabase) Delete(username string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return deleteUser(db.Users, username)
+}
+
+func (db *InMemoryDatabase) List(filter domain.UserFilter, page domain.Pagination) ([]domain.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return listUsers(db.Users, filter, page)
+}
+
+func (db *InMemoryDatabase) CompareAndSet(username string, value domain.User) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return compareAndSetUser(db.Users, username, value)
+}
+
+func (db *InMemoryDatabase) WithTx(fn func(tx interfaces.ITransaction) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
-	if _, ok := db.Users[username]; ok {
-		delete(db.Users, username)
-		return nil
+	tx := newTransaction(db.Users)
+	if err := fn(tx); err != nil {
+		return err
 	}
 
-	return errors.New("database delete | user not found")
+	tx.apply()
+	return nil
 }
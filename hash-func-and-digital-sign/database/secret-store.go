@@ -0,0 +1,90 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+)
+
+const (
+	secretLength = 32
+	secretIDSize = 8
+)
+
+type storedSecret struct {
+	keyID  string
+	secret []byte
+}
+
+type InMemorySecretStore struct {
+	mu      sync.RWMutex
+	secrets map[string][]storedSecret
+}
+
+func NewSecretStore() interfaces.ISecretStore {
+	return &InMemorySecretStore{secrets: make(map[string][]storedSecret)}
+}
+
+func (s *InMemorySecretStore) Add(username, keyID string, secret []byte) error {
+	if username == "" || keyID == "" || len(secret) == 0 {
+		return errors.New("secret store add | username, key id and secret are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.secrets[username] {
+		if stored.keyID == keyID {
+			return errors.New("secret store add | key id already exists")
+		}
+	}
+
+	s.secrets[username] = append(s.secrets[username], storedSecret{keyID: keyID, secret: append([]byte(nil), secret...)})
+	return nil
+}
+
+// Generate adds a random secret under a random key id.
+func (s *InMemorySecretStore) Generate(username string) (string, []byte, error) {
+	secret := make([]byte, secretLength)
+	id := make([]byte, secretIDSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	keyID := hex.EncodeToString(id)
+	if err := s.Add(username, keyID, secret); err != nil {
+		return "", nil, err
+	}
+	return keyID, secret, nil
+}
+
+func (s *InMemorySecretStore) Get(username, keyID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, stored := range s.secrets[username] {
+		if stored.keyID == keyID {
+			return append([]byte(nil), stored.secret...), nil
+		}
+	}
+	return nil, errors.New("secret store get | secret not found")
+}
+
+func (s *InMemorySecretStore) Active(username string) (string, []byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets := s.secrets[username]
+	if len(secrets) == 0 {
+		return "", nil, errors.New("secret store active | user has no secret")
+	}
+
+	active := secrets[len(secrets)-1]
+	return active.keyID, append([]byte(nil), active.secret...), nil
+}
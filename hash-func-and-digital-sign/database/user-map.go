@@ -0,0 +1,216 @@
+package database
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/keystore"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// The helpers below hold the IDatabase semantics shared by every database
+// that keeps its users in a map, so all of them version, list and
+// transact the same way. Callers are responsible for locking.
+
+// stored returns the stored user, a user saved before versioning or put in
+// the map directly has version 0 and counts as the first version, so that
+// version 0 always means the user does not exist.
+func stored(users map[string]domain.User, username string) (domain.User, bool) {
+	user, ok := users[username]
+	if ok && user.Version == 0 {
+		user.Version = 1
+	}
+	return user, ok
+}
+
+func getUser(users map[string]domain.User, username string) (domain.User, error) {
+	if user, ok := stored(users, username); ok {
+		return user, nil
+	}
+
+	return domain.User{}, errors.New("database get | user not found")
+}
+
+// setUser stores value with the next version of the stored user.
+func setUser(users map[string]domain.User, username string, value domain.User) {
+	current, _ := stored(users, username)
+	value.Version = current.Version + 1
+	users[username] = value
+}
+
+func deleteUser(users map[string]domain.User, username string) error {
+	if _, ok := users[username]; ok {
+		delete(users, username)
+		return nil
+	}
+
+	return errors.New("database delete | user not found")
+}
+
+func compareAndSetUser(users map[string]domain.User, username string, value domain.User) error {
+	if current, _ := stored(users, username); current.Version != value.Version {
+		return interfaces.ErrVersionConflict
+	}
+
+	setUser(users, username, value)
+	return nil
+}
+
+func listUsers(users map[string]domain.User, filter domain.UserFilter, page domain.Pagination) ([]domain.User, error) {
+	if page.Offset < 0 || page.Limit < 0 {
+		return nil, errors.New("database list | invalid pagination")
+	}
+
+	var usernames []string
+	for username := range users {
+		if strings.HasPrefix(username, filter.UsernamePrefix) {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+
+	if page.Offset >= len(usernames) {
+		return []domain.User{}, nil
+	}
+	usernames = usernames[page.Offset:]
+	if page.Limit > 0 && page.Limit < len(usernames) {
+		usernames = usernames[:page.Limit]
+	}
+
+	result := make([]domain.User, len(usernames))
+	for i, username := range usernames {
+		result[i], _ = stored(users, username)
+	}
+	return result, nil
+}
+
+// transaction stages writes on top of a user map without touching it,
+// a nil change marks a deleted user.
+type transaction struct {
+	users   map[string]domain.User
+	changes map[string]*domain.User
+}
+
+func newTransaction(users map[string]domain.User) *transaction {
+	return &transaction{users: users, changes: make(map[string]*domain.User)}
+}
+
+// view returns the user as seen inside the transaction.
+func (tx *transaction) view(username string) (domain.User, bool) {
+	if change, ok := tx.changes[username]; ok {
+		if change == nil {
+			return domain.User{}, false
+		}
+		return *change, true
+	}
+
+	return stored(tx.users, username)
+}
+
+func (tx *transaction) Get(username string) (domain.User, error) {
+	if user, ok := tx.view(username); ok {
+		return user, nil
+	}
+
+	return domain.User{}, errors.New("database get | user not found")
+}
+
+func (tx *transaction) Set(username string, value domain.User) error {
+	current, _ := tx.view(username)
+	value.Version = current.Version + 1
+	tx.changes[username] = &value
+	return nil
+}
+
+func (tx *transaction) Delete(username string) error {
+	if _, ok := tx.view(username); !ok {
+		return errors.New("database delete | user not found")
+	}
+
+	tx.changes[username] = nil
+	return nil
+}
+
+func (tx *transaction) CompareAndSet(username string, value domain.User) error {
+	current, _ := tx.view(username)
+	if current.Version != value.Version {
+		return interfaces.ErrVersionConflict
+	}
+
+	return tx.Set(username, value)
+}
+
+// apply writes the staged changes to the underlying map and returns the
+// previous state of every changed user, for undo.
+func (tx *transaction) apply() map[string]*domain.User {
+	users := tx.users
+	previous := make(map[string]*domain.User, len(tx.changes))
+
+	for username, change := range tx.changes {
+		if user, ok := users[username]; ok {
+			previous[username] = &user
+		} else {
+			previous[username] = nil
+		}
+
+		if change == nil {
+			delete(users, username)
+		} else {
+			users[username] = *change
+		}
+	}
+
+	return previous
+}
+
+// undo restores the state returned by apply.
+func undo(users map[string]domain.User, previous map[string]*domain.User) {
+	for username, user := range previous {
+		if user == nil {
+			delete(users, username)
+		} else {
+			users[username] = *user
+		}
+	}
+}
+
+// sealKeys replaces the plaintext private keys of the changed users with
+// keys sealed by keyring.
+func (tx *transaction) sealKeys(keyring *keystore.Keyring) error {
+	for _, change := range tx.changes {
+		if change == nil {
+			continue
+		}
+
+		// users stored before key rotation only have Key
+		if len(change.Keys) == 0 && change.Key != nil {
+			change.Keys = []domain.SigningKey{{
+				ID:     utils.GetKeyID(change.Key.Public()),
+				Key:    change.Key,
+				Status: domain.KeyActive,
+			}}
+		}
+
+		keys := make([]domain.SigningKey, len(change.Keys))
+		for i, key := range change.Keys {
+			keys[i] = key
+			if _, ok := key.Key.(*keystore.SealedKey); ok || key.Key == nil {
+				continue
+			}
+
+			sealed, err := keystore.Seal(keyring, key.Key)
+			if err != nil {
+				return err
+			}
+			if change.Key == key.Key {
+				change.Key = sealed
+			}
+			keys[i].Key = sealed
+		}
+		change.Keys = keys
+	}
+	return nil
+}
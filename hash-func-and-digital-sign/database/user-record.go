@@ -0,0 +1,176 @@
+package database
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/keystore"
+)
+
+const (
+	privateKeyPEMType = "PRIVATE KEY"
+	publicKeyPEMType  = "PUBLIC KEY"
+)
+
+// userRecord is the serialized form of domain.User used by persistent
+// databases, with private keys stored as PKCS#8 PEM blocks or, when sealed
+// by a keystore.Keyring, encrypted. Key holds the single key of a user
+// without Keys, as in records written before key rotation.
+type userRecord struct {
+	Username string      `json:"username"`
+	Password []byte      `json:"password"`
+	Key      string      `json:"key,omitempty"`
+	Keys     []keyRecord `json:"keys,omitempty"`
+	Version  uint64      `json:"version"`
+}
+
+type keyRecord struct {
+	ID        string                  `json:"id"`
+	Key       string                  `json:"key,omitempty"`
+	Sealed    *sealedKeyRecord        `json:"sealed,omitempty"`
+	Created   time.Time               `json:"created"`
+	Status    domain.KeyStatus        `json:"status"`
+	RevokedAt time.Time               `json:"revokedAt,omitempty"`
+	Reason    domain.RevocationReason `json:"reason,omitempty"`
+}
+
+type sealedKeyRecord struct {
+	PublicKey      string `json:"publicKey"`
+	MasterKeyID    string `json:"masterKeyId"`
+	WrappedDataKey []byte `json:"wrappedDataKey"`
+	Ciphertext     []byte `json:"ciphertext"`
+}
+
+func toRecord(user domain.User) (userRecord, error) {
+	record := userRecord{Username: user.Username, Password: user.Password, Version: user.Version}
+
+	for _, key := range user.Keys {
+		stored := keyRecord{
+			ID:        key.ID,
+			Created:   key.Created,
+			Status:    key.Status,
+			RevokedAt: key.RevokedAt,
+			Reason:    key.Reason,
+		}
+
+		var err error
+		if sealed, ok := key.Key.(*keystore.SealedKey); ok {
+			stored.Sealed, err = toSealedRecord(sealed)
+		} else {
+			stored.Key, err = encodePrivateKey(key.Key)
+		}
+		if err != nil {
+			return userRecord{}, err
+		}
+
+		record.Keys = append(record.Keys, stored)
+	}
+
+	if len(user.Keys) == 0 && user.Key != nil {
+		encoded, err := encodePrivateKey(user.Key)
+		if err != nil {
+			return userRecord{}, err
+		}
+		record.Key = encoded
+	}
+
+	return record, nil
+}
+
+// fromRecord restores sealed keys attached to keyring, which may be nil.
+func fromRecord(record userRecord, keyring *keystore.Keyring) (domain.User, error) {
+	user := domain.User{Username: record.Username, Password: record.Password, Version: record.Version}
+
+	for _, stored := range record.Keys {
+		var key crypto.Signer
+		var err error
+		if stored.Sealed != nil {
+			key, err = fromSealedRecord(*stored.Sealed, keyring)
+		} else {
+			key, err = decodePrivateKey(stored.Key)
+		}
+		if err != nil {
+			return domain.User{}, err
+		}
+
+		user.Keys = append(user.Keys, domain.SigningKey{
+			ID:        stored.ID,
+			Key:       key,
+			Created:   stored.Created,
+			Status:    stored.Status,
+			RevokedAt: stored.RevokedAt,
+			Reason:    stored.Reason,
+		})
+		if stored.Status == domain.KeyActive {
+			user.Key = key
+		}
+	}
+
+	if len(record.Keys) == 0 && record.Key != "" {
+		key, err := decodePrivateKey(record.Key)
+		if err != nil {
+			return domain.User{}, err
+		}
+		user.Key = key
+	}
+
+	return user, nil
+}
+
+func toSealedRecord(key *keystore.SealedKey) (*sealedKeyRecord, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sealedKeyRecord{
+		PublicKey:      string(pem.EncodeToMemory(&pem.Block{Type: publicKeyPEMType, Bytes: der})),
+		MasterKeyID:    key.MasterKeyID,
+		WrappedDataKey: key.WrappedDataKey,
+		Ciphertext:     key.Ciphertext,
+	}, nil
+}
+
+func fromSealedRecord(record sealedKeyRecord, keyring *keystore.Keyring) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(record.PublicKey))
+	if block == nil || block.Type != publicKeyPEMType {
+		return nil, errors.New("database | invalid public key encoding")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return keystore.Restore(keyring, publicKey, record.MasterKeyID, record.WrappedDataKey, record.Ciphertext), nil
+}
+
+func encodePrivateKey(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: der})), nil
+}
+
+func decodePrivateKey(encoded string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil || block.Type != privateKeyPEMType {
+		return nil, errors.New("database | invalid private key encoding")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("database | private key cannot sign")
+	}
+	return signer, nil
+}
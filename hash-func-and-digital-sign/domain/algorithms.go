@@ -0,0 +1,33 @@
+package domain
+
+// Hash algorithm identifiers recorded in signatures.
+const (
+	HashSHA224     = "SHA-224"
+	HashSHA256     = "SHA-256"
+	HashSHA384     = "SHA-384"
+	HashSHA512     = "SHA-512"
+	HashSHA3_256   = "SHA3-256"
+	HashSHA3_512   = "SHA3-512"
+	HashBLAKE2b256 = "BLAKE2b-256"
+	HashBLAKE2b512 = "BLAKE2b-512"
+	HashBLAKE2s256 = "BLAKE2s-256"
+)
+
+// Signature scheme identifiers recorded in signatures.
+const (
+	SchemePKCS1v15 = "RSASSA-PKCS1-v1_5"
+	SchemePSS      = "RSASSA-PSS"
+	// SchemeECDSA signatures are ASN.1 DER encoded.
+	SchemeECDSA = "ECDSA"
+	// SchemeHMAC marks messages authenticated with a shared secret.
+	SchemeHMAC = "HMAC"
+)
+
+// Key algorithm identifiers for generated signing keys.
+const (
+	KeyRSA2048   = "RSA-2048"
+	KeyRSA3072   = "RSA-3072"
+	KeyRSA4096   = "RSA-4096"
+	KeyECDSAP256 = "ECDSA-P256"
+	KeyECDSAP384 = "ECDSA-P384"
+)
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+const detachedSignatureV1 = 0x01
+
+// DetachedSignature signs the digest of content kept elsewhere, such as a
+// file, so the signature can travel as a separate .sig file.
+type DetachedSignature struct {
+	Signer          string    `json:"signer"`
+	KeyID           string    `json:"keyId"`
+	HashAlgorithm   string    `json:"hashAlgorithm"`
+	SignatureScheme string    `json:"signatureScheme"`
+	Timestamp       time.Time `json:"timestamp"`
+	Size            int64     `json:"size"`
+	Digest          []byte    `json:"digest"`
+	Signature       []byte    `json:"signature"`
+}
+
+// SigningInput returns the bytes the signature covers: the binary encoding
+// of every field except the signature itself.
+func (s DetachedSignature) SigningInput() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(detachedSignatureV1)
+	writeField(&buf, []byte(s.Signer))
+	writeField(&buf, []byte(s.KeyID))
+	writeField(&buf, []byte(s.HashAlgorithm))
+	writeField(&buf, []byte(s.SignatureScheme))
+	binary.Write(&buf, binary.BigEndian, s.Timestamp.UnixNano())
+	binary.Write(&buf, binary.BigEndian, s.Size)
+	writeField(&buf, s.Digest)
+	return buf.Bytes()
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+const multiSignedMessageV1 = 0x01
+
+// SignaturePolicy requires Threshold of the listed Signers to sign.
+type SignaturePolicy struct {
+	Signers   []string `json:"signers"`
+	Threshold int      `json:"threshold"`
+}
+
+// CoSignature is one signer's signature of a MultiSignedMessage.
+type CoSignature struct {
+	Signer          string    `json:"signer"`
+	KeyID           string    `json:"keyId"`
+	HashAlgorithm   string    `json:"hashAlgorithm"`
+	SignatureScheme string    `json:"signatureScheme"`
+	Timestamp       time.Time `json:"timestamp"`
+	Signature       []byte    `json:"signature"`
+}
+
+// MultiSignedMessage collects signatures of a payload until its policy is
+// satisfied. Every signature covers the payload, the policy and the creation
+// time, so none of them can change once someone signed.
+type MultiSignedMessage struct {
+	Payload    []byte          `json:"payload"`
+	Policy     SignaturePolicy `json:"policy"`
+	Created    time.Time       `json:"created"`
+	Signatures []CoSignature   `json:"signatures"`
+}
+
+// SigningInput returns the bytes signature covers: the document without
+// any signatures followed by the metadata of signature itself.
+func (m MultiSignedMessage) SigningInput(signature CoSignature) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(multiSignedMessageV1)
+	writeField(&buf, m.Payload)
+	binary.Write(&buf, binary.BigEndian, uint64(m.Policy.Threshold))
+	binary.Write(&buf, binary.BigEndian, uint64(len(m.Policy.Signers)))
+	for _, signer := range m.Policy.Signers {
+		writeField(&buf, []byte(signer))
+	}
+	binary.Write(&buf, binary.BigEndian, m.Created.UnixNano())
+
+	writeField(&buf, []byte(signature.Signer))
+	writeField(&buf, []byte(signature.KeyID))
+	writeField(&buf, []byte(signature.HashAlgorithm))
+	writeField(&buf, []byte(signature.SignatureScheme))
+	binary.Write(&buf, binary.BigEndian, signature.Timestamp.UnixNano())
+	return buf.Bytes()
+}
+
+// HasSigned reports whether signer has a signature on the message, valid or not.
+func (m MultiSignedMessage) HasSigned(signer string) bool {
+	for _, signature := range m.Signatures {
+		if signature.Signer == signer {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the listed signers that have not signed yet.
+func (m MultiSignedMessage) Missing() []string {
+	missing := []string{}
+	for _, signer := range m.Policy.Signers {
+		if !m.HasSigned(signer) {
+			missing = append(missing, signer)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,12 @@
+package domain
+
+type UserFilter struct {
+	UsernamePrefix string
+}
+
+// Pagination selects a window of a result sorted by username.
+// A zero Limit returns everything after Offset.
+type Pagination struct {
+	Offset int
+	Limit  int
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	signedMessageV1   = 0x01
+	maxBinaryFieldLen = 64 << 20
+)
+
+// SignedMessage is a self-contained envelope: it carries the payload, who
+// signed it with which key and how, so a verifier needs nothing else but the
+// signer's public key.
+type SignedMessage struct {
+	Payload         []byte    `json:"payload"`
+	Signer          string    `json:"signer"`
+	KeyID           string    `json:"keyId"`
+	HashAlgorithm   string    `json:"hashAlgorithm"`
+	SignatureScheme string    `json:"signatureScheme"`
+	Timestamp       time.Time `json:"timestamp"`
+	Signature       []byte    `json:"signature"`
+	// TimestampToken countersigns the digest of Signature, it is not covered
+	// by the signature itself.
+	TimestampToken *TimestampToken `json:"timestampToken,omitempty"`
+}
+
+// SigningInput returns the bytes the signature covers: the binary encoding
+// of every field except the signature itself.
+func (m SignedMessage) SigningInput() []byte {
+	var buf bytes.Buffer
+	m.writeFields(&buf)
+	return buf.Bytes()
+}
+
+// MarshalBinary encodes the envelope as a version byte followed by
+// uvarint length prefixed fields and the timestamp as big endian unix nanos.
+// A timestamp token, if any, follows the signature as one more field.
+func (m SignedMessage) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	m.writeFields(&buf)
+	writeField(&buf, m.Signature)
+
+	if m.TimestampToken != nil {
+		token, err := m.TimestampToken.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		writeField(&buf, token)
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *SignedMessage) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	version, err := r.ReadByte()
+	if err != nil || version != signedMessageV1 {
+		return errors.New("SignedMessage | unsupported encoding version")
+	}
+
+	var decoded SignedMessage
+	var fields [5][]byte
+	for i := range fields {
+		if fields[i], err = readField(r); err != nil {
+			return err
+		}
+	}
+
+	var nanos int64
+	if err := binary.Read(r, binary.BigEndian, &nanos); err != nil {
+		return errors.New("SignedMessage | truncated timestamp")
+	}
+
+	if decoded.Signature, err = readField(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		token, err := readField(r)
+		if err != nil {
+			return err
+		}
+		decoded.TimestampToken = &TimestampToken{}
+		if err := decoded.TimestampToken.UnmarshalBinary(token); err != nil {
+			return err
+		}
+	}
+	if r.Len() != 0 {
+		return errors.New("SignedMessage | trailing data")
+	}
+
+	decoded.Payload = fields[0]
+	decoded.Signer = string(fields[1])
+	decoded.KeyID = string(fields[2])
+	decoded.HashAlgorithm = string(fields[3])
+	decoded.SignatureScheme = string(fields[4])
+	decoded.Timestamp = time.Unix(0, nanos).UTC()
+
+	*m = decoded
+	return nil
+}
+
+func (m SignedMessage) writeFields(buf *bytes.Buffer) {
+	buf.WriteByte(signedMessageV1)
+	writeField(buf, m.Payload)
+	writeField(buf, []byte(m.Signer))
+	writeField(buf, []byte(m.KeyID))
+	writeField(buf, []byte(m.HashAlgorithm))
+	writeField(buf, []byte(m.SignatureScheme))
+	binary.Write(buf, binary.BigEndian, m.Timestamp.UnixNano())
+}
+
+func writeField(buf *bytes.Buffer, field []byte) {
+	var length [binary.MaxVarintLen64]byte
+	buf.Write(length[:binary.PutUvarint(length[:], uint64(len(field)))])
+	buf.Write(field)
+}
+
+func readField(r *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil || length > maxBinaryFieldLen || length > uint64(r.Len()) {
+		return nil, errors.New("SignedMessage | truncated field")
+	}
+
+	field := make([]byte, length)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, errors.New("SignedMessage | truncated field")
+	}
+	return field, nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"crypto"
+	"time"
+)
+
+type KeyStatus string
+
+const (
+	// KeyActive is the key new signatures are made with, a user has at most one.
+	KeyActive KeyStatus = "active"
+	// KeyRetired keys were rotated out, signatures made with them stay valid.
+	KeyRetired KeyStatus = "retired"
+	// KeyRevoked keys only verify signatures made before RevokedAt.
+	KeyRevoked KeyStatus = "revoked"
+)
+
+// RevocationReason follows the CRL reason codes of RFC 5280.
+type RevocationReason string
+
+const (
+	ReasonUnspecified          RevocationReason = "unspecified"
+	ReasonKeyCompromise        RevocationReason = "keyCompromise"
+	ReasonSuperseded           RevocationReason = "superseded"
+	ReasonCessationOfOperation RevocationReason = "cessationOfOperation"
+)
+
+type SigningKey struct {
+	ID        string
+	Key       crypto.Signer
+	Created   time.Time
+	Status    KeyStatus
+	RevokedAt time.Time
+	Reason    RevocationReason
+}
+
+// Revocation is an entry of the revocation list.
+type Revocation struct {
+	Username  string           `json:"username"`
+	KeyID     string           `json:"keyId"`
+	Reason    RevocationReason `json:"reason"`
+	RevokedAt time.Time        `json:"revokedAt"`
+}
+
+// ValidAt reports whether a signature made at signedAt may be accepted.
+func (k SigningKey) ValidAt(signedAt time.Time) bool {
+	return k.Status != KeyRevoked || signedAt.Before(k.RevokedAt)
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// TimestampTokenV1 is the only token version so far.
+const TimestampTokenV1 = 0x01
+
+// TimestampToken is a timestamp authority's countersignature over a digest,
+// modelled after the TSTInfo of RFC 3161 but encoded with the same framing as
+// SignedMessage rather than DER. Its signing input is
+//
+//	version byte (TimestampTokenV1)
+//	authority, keyId, hashAlgorithm, signatureScheme, messageImprint
+//	    as uvarint length prefixed fields
+//	serial as big endian uint64
+//	time as big endian int64 unix nanos
+//
+// and the binary encoding appends the signature as one more field.
+type TimestampToken struct {
+	Version         int    `json:"version"`
+	Authority       string `json:"authority"`
+	KeyID           string `json:"keyId"`
+	HashAlgorithm   string `json:"hashAlgorithm"`
+	SignatureScheme string `json:"signatureScheme"`
+	// MessageImprint is the digest that was timestamped, made with HashAlgorithm.
+	MessageImprint []byte    `json:"messageImprint"`
+	Serial         uint64    `json:"serial"`
+	Time           time.Time `json:"time"`
+	Signature      []byte    `json:"signature"`
+}
+
+func (t TimestampToken) SigningInput() []byte {
+	var buf bytes.Buffer
+	t.writeFields(&buf)
+	return buf.Bytes()
+}
+
+func (t TimestampToken) MarshalBinary() ([]byte, error) {
+	if t.Version != TimestampTokenV1 {
+		return nil, errors.New("TimestampToken | unsupported version")
+	}
+
+	var buf bytes.Buffer
+	t.writeFields(&buf)
+	writeField(&buf, t.Signature)
+	return buf.Bytes(), nil
+}
+
+func (t *TimestampToken) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	version, err := r.ReadByte()
+	if err != nil || version != TimestampTokenV1 {
+		return errors.New("TimestampToken | unsupported encoding version")
+	}
+
+	var fields [5][]byte
+	for i := range fields {
+		if fields[i], err = readField(r); err != nil {
+			return err
+		}
+	}
+
+	var serial uint64
+	var nanos int64
+	if err := binary.Read(r, binary.BigEndian, &serial); err != nil {
+		return errors.New("TimestampToken | truncated serial")
+	}
+	if err := binary.Read(r, binary.BigEndian, &nanos); err != nil {
+		return errors.New("TimestampToken | truncated time")
+	}
+
+	signature, err := readField(r)
+	if err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New("TimestampToken | trailing data")
+	}
+
+	*t = TimestampToken{
+		Version:         TimestampTokenV1,
+		Authority:       string(fields[0]),
+		KeyID:           string(fields[1]),
+		HashAlgorithm:   string(fields[2]),
+		SignatureScheme: string(fields[3]),
+		MessageImprint:  fields[4],
+		Serial:          serial,
+		Time:            time.Unix(0, nanos).UTC(),
+		Signature:       signature,
+	}
+	return nil
+}
+
+func (t TimestampToken) writeFields(buf *bytes.Buffer) {
+	buf.WriteByte(byte(t.Version))
+	writeField(buf, []byte(t.Authority))
+	writeField(buf, []byte(t.KeyID))
+	writeField(buf, []byte(t.HashAlgorithm))
+	writeField(buf, []byte(t.SignatureScheme))
+	writeField(buf, t.MessageImprint)
+	binary.Write(buf, binary.BigEndian, t.Serial)
+	binary.Write(buf, binary.BigEndian, t.Time.UnixNano())
+}
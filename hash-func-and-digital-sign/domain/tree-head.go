@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+const treeHeadV1 = 0x01
+
+// SignedTreeHead is the audit log's signed statement of its size and root
+// hash at a point in time.
+type SignedTreeHead struct {
+	TreeSize        uint64    `json:"treeSize"`
+	Timestamp       time.Time `json:"timestamp"`
+	RootHash        []byte    `json:"rootHash"`
+	KeyID           string    `json:"keyId"`
+	HashAlgorithm   string    `json:"hashAlgorithm"`
+	SignatureScheme string    `json:"signatureScheme"`
+	Signature       []byte    `json:"signature"`
+}
+
+// SigningInput returns the bytes the signature covers: the binary encoding
+// of every field except the signature itself.
+func (h SignedTreeHead) SigningInput() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(treeHeadV1)
+	binary.Write(&buf, binary.BigEndian, h.TreeSize)
+	binary.Write(&buf, binary.BigEndian, h.Timestamp.UnixNano())
+	writeField(&buf, h.RootHash)
+	writeField(&buf, []byte(h.KeyID))
+	writeField(&buf, []byte(h.HashAlgorithm))
+	writeField(&buf, []byte(h.SignatureScheme))
+	return buf.Bytes()
+}
@@ -1,9 +1,25 @@
 package domain
 
-import "crypto/rsa"
+import "crypto"
 
 type User struct {
 	Username string
 	Password []byte
-	Key      *rsa.PrivateKey
+	// Key is the active signing key, nil after it was revoked and until the
+	// next rotation.
+	Key crypto.Signer
+	// Keys holds every key the user ever had, including the active one.
+	Keys []SigningKey
+	// Version is bumped by the database on every write, see IDatabase.CompareAndSet.
+	Version uint64
+}
+
+// FindKey returns the key with the given id.
+func (u User) FindKey(keyID string) (SigningKey, bool) {
+	for _, key := range u.Keys {
+		if key.ID == keyID {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
 }
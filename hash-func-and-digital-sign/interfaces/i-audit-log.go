@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"crypto/rsa"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+)
+
+// IAuditLog is an append-only Merkle log of signed messages.
+type IAuditLog interface {
+	PublicKey() *rsa.PublicKey
+	Append(message domain.SignedMessage) (uint64, error)
+	Entry(index uint64) (domain.SignedMessage, error)
+	TreeHead() (domain.SignedTreeHead, error)
+	InclusionProof(index, treeSize uint64) ([][]byte, error)
+	ConsistencyProof(firstSize, secondSize uint64) ([][]byte, error)
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"crypto"
+	"crypto/x509"
+)
+
+type ICertificateAuthority interface {
+	Root() *x509.Certificate
+	Issue(username string, publicKey crypto.PublicKey) (*x509.Certificate, error)
+	Verify(certificate *x509.Certificate, username string) (crypto.PublicKey, error)
+}
@@ -1,9 +1,31 @@
 package interfaces
 
-import "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+import (
+	"errors"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+)
+
+// ErrVersionConflict is returned by CompareAndSet when the stored version
+// differs from the expected one.
+var ErrVersionConflict = errors.New("database | version conflict")
 
 type IDatabase interface {
 	Get(id string) (domain.User, error)
 	Set(id string, value domain.User) error
 	Delete(id string) error
+	List(filter domain.UserFilter, page domain.Pagination) ([]domain.User, error)
+	// CompareAndSet stores value only if the stored version equals
+	// value.Version, where version 0 means the user must not exist yet.
+	CompareAndSet(id string, value domain.User) error
+	// WithTx runs fn in a transaction that is committed if fn returns nil and
+	// discarded otherwise. fn must only use tx, not the database itself.
+	WithTx(fn func(tx ITransaction) error) error
+}
+
+type ITransaction interface {
+	Get(id string) (domain.User, error)
+	Set(id string, value domain.User) error
+	Delete(id string) error
+	CompareAndSet(id string, value domain.User) error
 }
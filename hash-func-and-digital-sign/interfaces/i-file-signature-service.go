@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"crypto"
+	"io"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+)
+
+type IFileSignatureService interface {
+	Sign(from domain.User, content io.Reader) (domain.DetachedSignature, error)
+	Check(publicKey crypto.PublicKey, content io.Reader, signature domain.DetachedSignature) error
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"crypto"
+	"crypto/x509"
+	"time"
+)
+
+// IKeyDirectory publishes the public half of registered users' keys.
+type IKeyDirectory interface {
+	Lookup(username, keyID string) (crypto.PublicKey, error)
+	// LookupAt fails for keys revoked at or before signedAt.
+	LookupAt(username, keyID string, signedAt time.Time) (crypto.PublicKey, error)
+	Certificate(username, keyID string) (*x509.Certificate, error)
+	Evict(username, keyID string)
+}
@@ -0,0 +1,7 @@
+package interfaces
+
+import "crypto"
+
+type IKeyGenerator interface {
+	Generate() (crypto.Signer, error)
+}
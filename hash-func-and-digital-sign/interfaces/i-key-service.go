@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+
+type IKeyService interface {
+	Rotate(username string) (domain.SigningKey, error)
+	Revoke(username, keyID string, reason domain.RevocationReason) error
+	RevocationList() ([]domain.Revocation, error)
+}
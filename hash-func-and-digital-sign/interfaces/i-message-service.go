@@ -1,12 +1,12 @@
 package interfaces
 
 import (
-	"crypto/rsa"
+	"crypto"
 
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
 )
 
 type IMessageService interface {
-	NewMessage(from domain.User, message string) (hashedMessage []byte, signature []byte, err error)
-	CheckMessage(publicKey *rsa.PublicKey, hashedMessage, signature []byte) error
+	NewMessage(from domain.User, message string) (domain.SignedMessage, error)
+	CheckMessage(publicKey crypto.PublicKey, message domain.SignedMessage) error
 }
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+
+type IMultiSignatureService interface {
+	NewDocument(payload string, policy domain.SignaturePolicy) (domain.MultiSignedMessage, error)
+	CoSign(from domain.User, document domain.MultiSignedMessage) (domain.MultiSignedMessage, error)
+	CheckDocument(document domain.MultiSignedMessage) error
+}
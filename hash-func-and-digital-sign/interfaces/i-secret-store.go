@@ -0,0 +1,10 @@
+package interfaces
+
+// ISecretStore keeps the shared secrets of users, each under a key id. The
+// most recently added secret of a user is the active one.
+type ISecretStore interface {
+	Add(username, keyID string, secret []byte) error
+	Generate(username string) (keyID string, secret []byte, err error)
+	Get(username, keyID string) ([]byte, error)
+	Active(username string) (keyID string, secret []byte, err error)
+}
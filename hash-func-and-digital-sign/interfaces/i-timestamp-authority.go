@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"crypto/rsa"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+)
+
+type ITimestampAuthority interface {
+	PublicKey() *rsa.PublicKey
+	Timestamp(hashAlgorithm string, digest []byte) (domain.TimestampToken, error)
+	Verify(token domain.TimestampToken, digest []byte) error
+}
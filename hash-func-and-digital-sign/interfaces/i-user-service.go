@@ -5,4 +5,10 @@ import "github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
 type IUserService interface {
 	Register(username, password string) error
 	Login(username, password string) (domain.User, error)
+	ChangePassword(username, oldPassword, newPassword string) error
+	// RequestPasswordReset returns a single use token to be sent to the user,
+	// for example as an email link, which ResetPassword accepts until it expires.
+	RequestPasswordReset(username string) (string, error)
+	ResetPassword(username, token, newPassword string) error
+	Delete(username, password string) error
 }
@@ -0,0 +1,149 @@
+// Package keystore encrypts private keys at rest with envelope encryption:
+// every private key is encrypted with its own random data key, and the data
+// key is wrapped with a master key kept outside the database.
+package keystore
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MasterKeySize is the size of master and data keys, both are AES-256 keys.
+const MasterKeySize = 32
+
+var (
+	ErrUnknownMasterKey = errors.New("keystore | unknown master key")
+	ErrNoKeyring        = errors.New("keystore | no master key to unwrap with")
+)
+
+// Keyring holds the master keys by id. New data keys are wrapped with the
+// current one, older ones stay available to unwrap until every key was
+// rewrapped.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	current string
+}
+
+func NewKeyring(id string, masterKey []byte) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string][]byte)}
+	if err := k.Add(id, masterKey); err != nil {
+		return nil, err
+	}
+	k.current = id
+	return k, nil
+}
+
+// ParseKeyring reads comma separated id:base64key entries, the first one is
+// the current master key.
+func ParseKeyring(text string) (*Keyring, error) {
+	var keyring *Keyring
+	for _, entry := range strings.Split(strings.TrimSpace(text), ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("keystore | master keys must be id:base64key entries")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("keystore | master key %q is not base64", parts[0])
+		}
+
+		if keyring == nil {
+			if keyring, err = NewKeyring(parts[0], key); err != nil {
+				return nil, err
+			}
+		} else if err := keyring.Add(parts[0], key); err != nil {
+			return nil, err
+		}
+	}
+	return keyring, nil
+}
+
+func LoadKeyringFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyring(string(data))
+}
+
+func LoadKeyringEnv(name string) (*Keyring, error) {
+	text := os.Getenv(name)
+	if text == "" {
+		return nil, fmt.Errorf("keystore | %s is not set", name)
+	}
+	return ParseKeyring(text)
+}
+
+// Add makes an older master key available for unwrapping.
+func (k *Keyring) Add(id string, masterKey []byte) error {
+	if id == "" || len(masterKey) != MasterKeySize {
+		return fmt.Errorf("keystore | master key needs an id and %d bytes", MasterKeySize)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("keystore | master key %q already exists", id)
+	}
+	k.keys[id] = append([]byte(nil), masterKey...)
+	return nil
+}
+
+// Rotate adds a new master key and makes it the current one.
+func (k *Keyring) Rotate(id string, masterKey []byte) error {
+	if err := k.Add(id, masterKey); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.current = id
+	return nil
+}
+
+// Remove drops a master key that no data key is wrapped with anymore.
+func (k *Keyring) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if id == k.current {
+		return errors.New("keystore | cannot remove the current master key")
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+func (k *Keyring) Current() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.current
+}
+
+func (k *Keyring) wrap(dataKey []byte) (string, []byte, error) {
+	k.mu.RLock()
+	id, masterKey := k.current, k.keys[k.current]
+	k.mu.RUnlock()
+
+	wrapped, err := seal(masterKey, dataKey, []byte(id))
+	return id, wrapped, err
+}
+
+func (k *Keyring) unwrap(id string, wrapped []byte) ([]byte, error) {
+	k.mu.RLock()
+	masterKey, ok := k.keys[id]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownMasterKey
+	}
+	return open(masterKey, wrapped, []byte(id))
+}
@@ -0,0 +1,188 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"io"
+)
+
+// SealedKey is a private key encrypted under its own data key. It signs like
+// the key it seals, unwrapping the data key and decrypting the private key
+// only for the duration of each Sign call.
+type SealedKey struct {
+	PublicKey      crypto.PublicKey
+	MasterKeyID    string
+	WrappedDataKey []byte
+	// Ciphertext is the AES-256-GCM encrypted PKCS#8 encoding of the key,
+	// bound to the PKIX encoding of PublicKey.
+	Ciphertext []byte
+
+	keyring *Keyring
+}
+
+// Seal encrypts key with a fresh data key wrapped by the current master key.
+func Seal(keyring *Keyring, key crypto.Signer) (*SealedKey, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(der)
+
+	publicDer, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, MasterKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	defer zero(dataKey)
+
+	ciphertext, err := seal(dataKey, der, publicDer)
+	if err != nil {
+		return nil, err
+	}
+
+	masterKeyID, wrapped, err := keyring.wrap(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SealedKey{
+		PublicKey:      key.Public(),
+		MasterKeyID:    masterKeyID,
+		WrappedDataKey: wrapped,
+		Ciphertext:     ciphertext,
+		keyring:        keyring,
+	}, nil
+}
+
+// Restore attaches a sealed key loaded from storage to keyring, which may be
+// nil for verification only use.
+func Restore(keyring *Keyring, publicKey crypto.PublicKey, masterKeyID string, wrappedDataKey, ciphertext []byte) *SealedKey {
+	return &SealedKey{
+		PublicKey:      publicKey,
+		MasterKeyID:    masterKeyID,
+		WrappedDataKey: wrappedDataKey,
+		Ciphertext:     ciphertext,
+		keyring:        keyring,
+	}
+}
+
+func (k *SealedKey) Public() crypto.PublicKey {
+	return k.PublicKey
+}
+
+func (k *SealedKey) Sign(reader io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	key, der, err := k.unseal()
+	if err != nil {
+		return nil, err
+	}
+	defer zero(der)
+
+	return key.Sign(reader, digest, opts)
+}
+
+// Rewrap returns the key with its data key wrapped by the current master key
+// of keyring, which must also hold the master key it is wrapped with now.
+// The encrypted private key itself does not change.
+func (k *SealedKey) Rewrap(keyring *Keyring) (*SealedKey, error) {
+	dataKey, err := keyring.unwrap(k.MasterKeyID, k.WrappedDataKey)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(dataKey)
+
+	masterKeyID, wrapped, err := keyring.wrap(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return Restore(keyring, k.PublicKey, masterKeyID, wrapped, k.Ciphertext), nil
+}
+
+func (k *SealedKey) unseal() (crypto.Signer, []byte, error) {
+	if k.keyring == nil {
+		return nil, nil, ErrNoKeyring
+	}
+
+	dataKey, err := k.keyring.unwrap(k.MasterKeyID, k.WrappedDataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zero(dataKey)
+
+	publicDer, err := x509.MarshalPKIXPublicKey(k.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	der, err := open(dataKey, k.Ciphertext, publicDer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		zero(der)
+		return nil, nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		zero(der)
+		return nil, nil, errors.New("keystore | sealed key cannot sign")
+	}
+	return signer, der, nil
+}
+
+// seal encrypts with AES-256-GCM and prepends the random nonce.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("keystore | ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.New("keystore | decryption failed")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,190 @@
+// Package merkle implements the Merkle tree hashing, inclusion proofs and
+// consistency proofs of Certificate Transparency (RFC 6962, RFC 9162) with
+// SHA-256.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+var (
+	ErrInvalidIndex = errors.New("merkle | index out of range")
+	ErrInvalidProof = errors.New("merkle | proof does not verify")
+)
+
+// LeafHash returns the hash of a log entry, domain separated from inner nodes.
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// RootHash returns the Merkle tree hash of the given leaf hashes, the empty
+// tree hashes to SHA-256 of the empty string.
+func RootHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	case 1:
+		return leaves[0]
+	}
+
+	k := split(len(leaves))
+	return nodeHash(RootHash(leaves[:k]), RootHash(leaves[k:]))
+}
+
+// InclusionProof returns the audit path of leaf index in the tree of leaves.
+func InclusionProof(leaves [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrInvalidIndex
+	}
+	return path(leaves, index), nil
+}
+
+// ConsistencyProof proves that the tree of the first size leaves is a prefix
+// of the tree of leaves.
+func ConsistencyProof(leaves [][]byte, size int) ([][]byte, error) {
+	if size < 0 || size > len(leaves) {
+		return nil, ErrInvalidIndex
+	}
+	if size == 0 || size == len(leaves) {
+		return [][]byte{}, nil
+	}
+	return subproof(leaves, size, true), nil
+}
+
+// VerifyInclusion checks that leafHash is at index of the tree of treeSize
+// leaves with the given root.
+func VerifyInclusion(index, treeSize uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	if index >= treeSize {
+		return ErrInvalidIndex
+	}
+
+	fn, sn := index, treeSize-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(r, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// VerifyConsistency checks that the tree of firstSize leaves with firstRoot
+// is a prefix of the tree of secondSize leaves with secondRoot.
+func VerifyConsistency(firstSize, secondSize uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	switch {
+	case firstSize > secondSize:
+		return ErrInvalidIndex
+	case firstSize == secondSize:
+		if len(proof) != 0 || !bytes.Equal(firstRoot, secondRoot) {
+			return ErrInvalidProof
+		}
+		return nil
+	case firstSize == 0:
+		if len(proof) != 0 {
+			return ErrInvalidProof
+		}
+		return nil
+	}
+
+	if firstSize&(firstSize-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return ErrInvalidProof
+	}
+
+	fn, sn := firstSize-1, secondSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = nodeHash(c, fr)
+			sr = nodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// split returns the largest power of two smaller than n, n > 1.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+func path(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return [][]byte{}
+	}
+
+	k := split(len(leaves))
+	if index < k {
+		return append(path(leaves[:k], index), RootHash(leaves[k:]))
+	}
+	return append(path(leaves[k:], index-k), RootHash(leaves[:k]))
+}
+
+func subproof(leaves [][]byte, size int, complete bool) [][]byte {
+	if size == len(leaves) {
+		if complete {
+			return [][]byte{}
+		}
+		return [][]byte{RootHash(leaves)}
+	}
+
+	k := split(len(leaves))
+	if size <= k {
+		return append(subproof(leaves[:k], size, complete), RootHash(leaves[k:]))
+	}
+	return append(subproof(leaves[k:], size-k, false), RootHash(leaves[:k]))
+}
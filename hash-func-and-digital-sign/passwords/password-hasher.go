@@ -0,0 +1,246 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+type Algorithm string
+
+const (
+	Argon2id Algorithm = "argon2id"
+	Bcrypt   Algorithm = "bcrypt"
+	Scrypt   Algorithm = "scrypt"
+)
+
+var (
+	ErrMismatch      = errors.New("passwords | password does not match")
+	ErrInvalidFormat = errors.New("passwords | invalid hash format")
+)
+
+// Upper bounds on the work factors accepted from a stored hash, so a
+// corrupted or planted hash cannot make Verify allocate or spin without
+// limit.
+const (
+	maxArgon2Memory     = 1024 * 1024 // KiB, 1 GiB
+	maxArgon2Iterations = 64
+	maxScryptLogN       = 22
+	maxScryptR          = 32
+	maxScryptP          = 16
+)
+
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type ScryptParams struct {
+	LogN       uint8 // N = 2^LogN
+	R          int
+	P          int
+	SaltLength int
+	KeyLength  int
+}
+
+// Hasher produces PHC formatted password hashes with Algorithm and verifies
+// hashes produced by any supported algorithm.
+type Hasher struct {
+	Algorithm  Algorithm
+	Argon2     Argon2Params
+	Scrypt     ScryptParams
+	BcryptCost int
+}
+
+// NewHasher returns a Hasher with the OWASP recommended Argon2id parameters.
+func NewHasher() *Hasher {
+	return &Hasher{
+		Algorithm: Argon2id,
+		Argon2: Argon2Params{
+			Memory:      19 * 1024,
+			Iterations:  2,
+			Parallelism: 1,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+		Scrypt: ScryptParams{
+			LogN:       17,
+			R:          8,
+			P:          1,
+			SaltLength: 16,
+			KeyLength:  32,
+		},
+		BcryptCost: 12,
+	}
+}
+
+// Hash hashes password with the configured algorithm and returns a PHC string:
+//
+//	$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+//	$scrypt$ln=17,r=8,p=1$<salt>$<hash>
+//	$2a$12$<salt and hash>
+func (h *Hasher) Hash(password string) (string, error) {
+	switch h.Algorithm {
+	case Argon2id:
+		p := h.Argon2
+		salt, err := randomSalt(int(p.SaltLength))
+		if err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, p.Memory, p.Iterations, p.Parallelism, encode(salt), encode(key)), nil
+
+	case Scrypt:
+		p := h.Scrypt
+		salt, err := randomSalt(p.SaltLength)
+		if err != nil {
+			return "", err
+		}
+		key, err := scrypt.Key([]byte(password), salt, 1<<p.LogN, p.R, p.P, p.KeyLength)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+			p.LogN, p.R, p.P, encode(salt), encode(key)), nil
+
+	case Bcrypt:
+		key, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(key), nil
+	}
+
+	return "", fmt.Errorf("passwords | unsupported algorithm %q", h.Algorithm)
+}
+
+// Verify checks password against encoded in constant time. needsRehash
+// reports whether encoded was produced with another algorithm or other
+// parameters than the Hasher's, so callers can store a fresh Hash.
+func (h *Hasher) Verify(password, encoded string) (needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		var version int
+		var p Argon2Params
+		salt, key, err := parsePHC(encoded, 6, func(parts []string) error {
+			if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+				return err
+			}
+			_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+		if version != argon2.Version {
+			return false, ErrInvalidFormat
+		}
+		// argon2.IDKey panics on zero iterations or parallelism
+		if p.Iterations < 1 || p.Iterations > maxArgon2Iterations || p.Parallelism < 1 || p.Memory > maxArgon2Memory {
+			return false, ErrInvalidFormat
+		}
+		p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+
+		actual := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+		if subtle.ConstantTimeCompare(actual, key) != 1 {
+			return false, ErrMismatch
+		}
+		return h.Algorithm != Argon2id || p != h.Argon2, nil
+
+	case strings.HasPrefix(encoded, "$scrypt$"):
+		var p ScryptParams
+		salt, key, err := parsePHC(encoded, 5, func(parts []string) error {
+			_, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &p.LogN, &p.R, &p.P)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+		// scrypt.Key divides by r and p, so neither can be zero
+		if p.LogN < 1 || p.LogN > maxScryptLogN || p.R < 1 || p.R > maxScryptR || p.P < 1 || p.P > maxScryptP {
+			return false, ErrInvalidFormat
+		}
+		p.SaltLength, p.KeyLength = len(salt), len(key)
+
+		actual, err := scrypt.Key([]byte(password), salt, 1<<p.LogN, p.R, p.P, p.KeyLength)
+		if err != nil {
+			return false, err
+		}
+		if subtle.ConstantTimeCompare(actual, key) != 1 {
+			return false, ErrMismatch
+		}
+		return h.Algorithm != Scrypt || p != h.Scrypt, nil
+
+	case strings.HasPrefix(encoded, "$2"):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, ErrMismatch
+		}
+		if err != nil {
+			return false, ErrInvalidFormat
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return false, ErrInvalidFormat
+		}
+		return h.Algorithm != Bcrypt || cost != h.BcryptCost, nil
+
+	// unsalted SHA-256 digests stored before this package existed,
+	// they can only be verified and always need a rehash
+	case len(encoded) == sha256.Size:
+		digest := sha256.Sum256([]byte(password))
+		if subtle.ConstantTimeCompare(digest[:], []byte(encoded)) != 1 {
+			return false, ErrMismatch
+		}
+		return true, nil
+	}
+
+	return false, ErrInvalidFormat
+}
+
+// parsePHC splits encoded into fields, lets parseParams read the algorithm
+// specific ones and decodes the trailing salt and hash.
+func parsePHC(encoded string, fields int, parseParams func(parts []string) error) (salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != fields {
+		return nil, nil, ErrInvalidFormat
+	}
+	if err := parseParams(parts); err != nil {
+		return nil, nil, ErrInvalidFormat
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[fields-2])
+	if err != nil {
+		return nil, nil, ErrInvalidFormat
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[fields-1])
+	if err != nil || len(key) == 0 {
+		return nil, nil, ErrInvalidFormat
+	}
+
+	return salt, key, nil
+}
+
+func randomSalt(length int) ([]byte, error) {
+	salt := make([]byte, length)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+func encode(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
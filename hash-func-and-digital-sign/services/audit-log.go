@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/merkle"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// AuditLog keeps every appended message in a Certificate Transparency style
+// Merkle tree. The leaf of a message is its binary encoding, so neither the
+// message, its signature nor its timestamp token can change unnoticed.
+type AuditLog struct {
+	key     *rsa.PrivateKey
+	mu      sync.RWMutex
+	entries []domain.SignedMessage
+	leaves  [][]byte
+}
+
+func NewAuditLog() (interfaces.IAuditLog, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{key: key}, nil
+}
+
+func (l *AuditLog) PublicKey() *rsa.PublicKey {
+	return &l.key.PublicKey
+}
+
+func (l *AuditLog) Append(message domain.SignedMessage) (uint64, error) {
+	data, err := message.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, message)
+	l.leaves = append(l.leaves, merkle.LeafHash(data))
+	return uint64(len(l.entries) - 1), nil
+}
+
+func (l *AuditLog) Entry(index uint64) (domain.SignedMessage, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if index >= uint64(len(l.entries)) {
+		return domain.SignedMessage{}, errors.New("AuditLog Entry | Index out of range")
+	}
+	return l.entries[index], nil
+}
+
+// TreeHead signs the current size and root hash of the log.
+func (l *AuditLog) TreeHead() (domain.SignedTreeHead, error) {
+	l.mu.RLock()
+	head := domain.SignedTreeHead{
+		TreeSize:        uint64(len(l.leaves)),
+		Timestamp:       time.Now().UTC(),
+		RootHash:        merkle.RootHash(l.leaves),
+		KeyID:           utils.GetKeyID(&l.key.PublicKey),
+		HashAlgorithm:   domain.HashSHA256,
+		SignatureScheme: domain.SchemePSS,
+	}
+	l.mu.RUnlock()
+
+	signature, err := signInput(l.key, head.HashAlgorithm, head.SignatureScheme, head.SigningInput())
+	if err != nil {
+		return domain.SignedTreeHead{}, errors.New("AuditLog TreeHead | Could not sign tree head")
+	}
+
+	head.Signature = signature
+	return head, nil
+}
+
+// InclusionProof proves that the entry at index is part of the tree of the
+// first treeSize entries.
+func (l *AuditLog) InclusionProof(index, treeSize uint64) ([][]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if treeSize > uint64(len(l.leaves)) {
+		return nil, errors.New("AuditLog InclusionProof | Tree size out of range")
+	}
+	return merkle.InclusionProof(l.leaves[:treeSize], int(index))
+}
+
+// ConsistencyProof proves that the tree of the first firstSize entries is a
+// prefix of the tree of the first secondSize entries.
+func (l *AuditLog) ConsistencyProof(firstSize, secondSize uint64) ([][]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if secondSize > uint64(len(l.leaves)) {
+		return nil, errors.New("AuditLog ConsistencyProof | Tree size out of range")
+	}
+	return merkle.ConsistencyProof(l.leaves[:secondSize], int(firstSize))
+}
+
+// VerifyTreeHead checks the signature of a tree head with the log's key.
+func VerifyTreeHead(publicKey *rsa.PublicKey, head domain.SignedTreeHead) error {
+	if head.KeyID != utils.GetKeyID(publicKey) {
+		return errors.New("AuditLog | Tree head was signed by another log")
+	}
+
+	err := verifyInput(publicKey, head.HashAlgorithm, head.SignatureScheme, head.SigningInput(), head.Signature)
+	if err != nil {
+		return errors.New("AuditLog | Tree head signature check failed")
+	}
+	return nil
+}
+
+// VerifyMessageInclusion checks that message is the entry at index of the
+// tree described by head. The head itself must be checked with VerifyTreeHead.
+func VerifyMessageInclusion(head domain.SignedTreeHead, message domain.SignedMessage, index uint64, proof [][]byte) error {
+	data, err := message.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return merkle.VerifyInclusion(index, head.TreeSize, merkle.LeafHash(data), proof, head.RootHash)
+}
+
+// VerifyTreeConsistency checks that the log only appended entries between
+// the first and the second tree head.
+func VerifyTreeConsistency(first, second domain.SignedTreeHead, proof [][]byte) error {
+	return merkle.VerifyConsistency(first.TreeSize, second.TreeSize, first.RootHash, second.RootHash, proof)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+const (
+	rootValidity        = 10 * 365 * 24 * time.Hour
+	certificateValidity = 365 * 24 * time.Hour
+)
+
+// CertificateAuthority is a single level CA: a self-signed root that issues
+// leaf certificates binding a username to its public key.
+type CertificateAuthority struct {
+	key  *rsa.PrivateKey
+	root *x509.Certificate
+}
+
+func NewCertificateAuthority(name string) (interfaces.ICertificateAuthority, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(rootValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertificateAuthority{key: key, root: root}, nil
+}
+
+func (ca *CertificateAuthority) Root() *x509.Certificate {
+	return ca.root
+}
+
+// Issue certifies that publicKey belongs to username. The subject key id of
+// the certificate is the key id used in signatures.
+func (ca *CertificateAuthority) Issue(username string, publicKey crypto.PublicKey) (*x509.Certificate, error) {
+	if username == "" || publicKey == nil {
+		return nil, errors.New("CertificateAuthority Issue | Username and public key are required")
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, err := hex.DecodeString(utils.GetKeyID(publicKey))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: username},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(certificateValidity),
+		SubjectKeyId:          keyID,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.root, publicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// Verify validates the chain of certificate up to the root and that it was
+// issued to username, then returns the certified public key.
+func (ca *CertificateAuthority) Verify(certificate *x509.Certificate, username string) (crypto.PublicKey, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.root)
+
+	_, err := certificate.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return nil, errors.New("CertificateAuthority Verify | Invalid certificate chain: " + err.Error())
+	}
+
+	if certificate.Subject.CommonName != username {
+		return nil, errors.New("CertificateAuthority Verify | Certificate was issued to another user")
+	}
+
+	return certificate.PublicKey, nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
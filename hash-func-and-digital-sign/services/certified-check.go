@@ -0,0 +1,73 @@
+package services
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// CheckCertifiedMessage validates the signer's certificate against authority
+// and rejects keys directory knows as revoked before the message timestamp,
+// since a certificate outlives the revocation of its key. The message is then
+// checked with the certified key.
+func CheckCertifiedMessage(messages interfaces.IMessageService, authority interfaces.ICertificateAuthority, directory interfaces.IKeyDirectory, certificate *x509.Certificate, message domain.SignedMessage) error {
+	publicKey, err := authority.Verify(certificate, message.Signer)
+	if err != nil {
+		return err
+	}
+
+	if utils.GetKeyID(publicKey) != message.KeyID {
+		return errors.New("CheckCertifiedMessage | Certificate is for another key")
+	}
+
+	if _, err := directory.LookupAt(message.Signer, message.KeyID, message.Timestamp); err != nil {
+		return err
+	}
+
+	return messages.CheckMessage(publicKey, message)
+}
+
+// CheckPublishedMessage looks the signer's key up in directory, rejecting
+// keys revoked before the message timestamp, and checks the message with it.
+// The timestamp is asserted by the signer, so whoever holds a compromised
+// key can still backdate signatures, see CheckTimestampedMessage.
+func CheckPublishedMessage(messages interfaces.IMessageService, directory interfaces.IKeyDirectory, message domain.SignedMessage) error {
+	publicKey, err := directory.LookupAt(message.Signer, message.KeyID, message.Timestamp)
+	if err != nil {
+		return err
+	}
+	return messages.CheckMessage(publicKey, message)
+}
+
+// CheckPublishedFile is CheckPublishedMessage for detached signatures.
+func CheckPublishedFile(files interfaces.IFileSignatureService, directory interfaces.IKeyDirectory, content io.Reader, signature domain.DetachedSignature) error {
+	publicKey, err := directory.LookupAt(signature.Signer, signature.KeyID, signature.Timestamp)
+	if err != nil {
+		return err
+	}
+	return files.Check(publicKey, content, signature)
+}
+
+// CheckTimestampedMessage is CheckPublishedMessage with the signing time
+// taken from the message's timestamp token, which authority must have
+// issued, instead of the time claimed by the signer.
+func CheckTimestampedMessage(messages interfaces.IMessageService, directory interfaces.IKeyDirectory, authority interfaces.ITimestampAuthority, message domain.SignedMessage) error {
+	if message.TimestampToken == nil {
+		return errors.New("CheckTimestampedMessage | Message has no timestamp token")
+	}
+
+	token := *message.TimestampToken
+	if err := checkSignatureTimestamp(authority, token, message.Signature); err != nil {
+		return err
+	}
+
+	publicKey, err := directory.LookupAt(message.Signer, message.KeyID, token.Time)
+	if err != nil {
+		return err
+	}
+	return messages.CheckMessage(publicKey, message)
+}
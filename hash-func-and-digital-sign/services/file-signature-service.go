@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// FileSignatureService signs content of any size by hashing it as a stream,
+// the content itself is never held in memory.
+type FileSignatureService struct {
+	// HashAlgorithm is used for new signatures, existing ones are checked
+	// with the algorithm recorded in them.
+	HashAlgorithm string
+}
+
+func NewFileSignatureService() interfaces.IFileSignatureService {
+	return &FileSignatureService{HashAlgorithm: domain.HashSHA256}
+}
+
+func NewFileSignatureServiceWithHash(hashAlgorithm string) (interfaces.IFileSignatureService, error) {
+	if _, err := utils.LookupHash(hashAlgorithm); err != nil {
+		return nil, err
+	}
+	return &FileSignatureService{HashAlgorithm: hashAlgorithm}, nil
+}
+
+func (s *FileSignatureService) Sign(from domain.User, content io.Reader) (domain.DetachedSignature, error) {
+	if from.Key == nil {
+		return domain.DetachedSignature{}, errors.New("FileSignatureService | User has no active signing key")
+	}
+
+	scheme, err := defaultScheme(from.Key.Public(), s.HashAlgorithm)
+	if err != nil {
+		return domain.DetachedSignature{}, err
+	}
+
+	digest, size, err := utils.GetStreamDigest(s.HashAlgorithm, content)
+	if err != nil {
+		return domain.DetachedSignature{}, err
+	}
+
+	signed := domain.DetachedSignature{
+		Signer:          from.Username,
+		KeyID:           utils.GetKeyID(from.Key.Public()),
+		HashAlgorithm:   s.HashAlgorithm,
+		SignatureScheme: scheme,
+		Timestamp:       time.Now().UTC(),
+		Size:            size,
+		Digest:          digest,
+	}
+
+	signature, err := signInput(from.Key, s.HashAlgorithm, scheme, signed.SigningInput())
+	if err != nil {
+		return domain.DetachedSignature{}, errors.New("FileSignatureService | Could not sign content")
+	}
+
+	signed.Signature = signature
+	return signed, nil
+}
+
+func (s *FileSignatureService) Check(publicKey crypto.PublicKey, content io.Reader, signature domain.DetachedSignature) error {
+	if signature.KeyID != utils.GetKeyID(publicKey) {
+		return errors.New("FileSignatureService | Content was signed with another key")
+	}
+
+	err := verifyInput(publicKey, signature.HashAlgorithm, signature.SignatureScheme, signature.SigningInput(), signature.Signature)
+	if err != nil {
+		return errors.New("FileSignatureService | Signature check failed")
+	}
+
+	digest, size, err := utils.GetStreamDigest(signature.HashAlgorithm, content)
+	if err != nil {
+		return err
+	}
+
+	if size != signature.Size || !bytes.Equal(digest, signature.Digest) {
+		return errors.New("FileSignatureService | Content does not match the signature")
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"errors"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// HmacMessageService authenticates messages with the sender's shared secret
+// instead of a signature, for services that trust each other. Anyone holding
+// the secret can create messages, so they prove nothing to third parties.
+type HmacMessageService struct {
+	Secrets interfaces.ISecretStore
+	// HashAlgorithm is used for new messages, existing ones are checked
+	// with the algorithm recorded in them.
+	HashAlgorithm string
+}
+
+func NewHmacMessageService(secrets interfaces.ISecretStore) interfaces.IMessageService {
+	return &HmacMessageService{Secrets: secrets, HashAlgorithm: domain.HashSHA256}
+}
+
+func NewHmacMessageServiceWithHash(secrets interfaces.ISecretStore, hashAlgorithm string) (interfaces.IMessageService, error) {
+	if _, err := utils.LookupHash(hashAlgorithm); err != nil {
+		return nil, err
+	}
+	return &HmacMessageService{Secrets: secrets, HashAlgorithm: hashAlgorithm}, nil
+}
+
+// NewMessage authenticates message with the active secret of from, the key
+// of from is not used.
+func (s *HmacMessageService) NewMessage(from domain.User, message string) (domain.SignedMessage, error) {
+	keyID, secret, err := s.Secrets.Active(from.Username)
+	if err != nil {
+		return domain.SignedMessage{}, errors.New("HmacMessageService | User has no shared secret")
+	}
+
+	signed := domain.SignedMessage{
+		Payload:         []byte(message),
+		Signer:          from.Username,
+		KeyID:           keyID,
+		HashAlgorithm:   s.HashAlgorithm,
+		SignatureScheme: domain.SchemeHMAC,
+		Timestamp:       time.Now().UTC(),
+	}
+
+	signed.Signature, err = computeMac(secret, s.HashAlgorithm, signed.SigningInput())
+	if err != nil {
+		return domain.SignedMessage{}, err
+	}
+	return signed, nil
+}
+
+// CheckMessage verifies the MAC with the secret the message names.
+// publicKey is not used and may be nil.
+func (s *HmacMessageService) CheckMessage(publicKey crypto.PublicKey, message domain.SignedMessage) error {
+	if message.SignatureScheme != domain.SchemeHMAC {
+		return errors.New("HmacMessageService | Message is not authenticated with HMAC")
+	}
+
+	secret, err := s.Secrets.Get(message.Signer, message.KeyID)
+	if err != nil {
+		return errors.New("HmacMessageService | Unknown sender or key id")
+	}
+
+	expected, err := computeMac(secret, message.HashAlgorithm, message.SigningInput())
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(expected, message.Signature) {
+		return errors.New("HmacMessageService | Message authentication failed")
+	}
+	return nil
+}
+
+func computeMac(secret []byte, hashAlgorithm string, input []byte) ([]byte, error) {
+	hash, err := utils.LookupHash(hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(hash.New, secret)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+)
+
+// KeyDirectoryService answers public key lookups from the user database and
+// hands out certificates issued by Authority for keys that are not revoked,
+// so verifiers never need the user record itself.
+type KeyDirectoryService struct {
+	Users     interfaces.IDatabase
+	Authority interfaces.ICertificateAuthority
+
+	mu           sync.Mutex
+	certificates map[string]*x509.Certificate
+}
+
+func NewKeyDirectoryService(db interfaces.IDatabase, authority interfaces.ICertificateAuthority) interfaces.IKeyDirectory {
+	return &KeyDirectoryService{
+		Users:        db,
+		Authority:    authority,
+		certificates: make(map[string]*x509.Certificate),
+	}
+}
+
+func (s *KeyDirectoryService) Lookup(username, keyID string) (crypto.PublicKey, error) {
+	user, err := s.Users.Get(username)
+	if err != nil {
+		return nil, errors.New("KeyDirectoryService Lookup | Unknown user")
+	}
+
+	key, ok := findUserKey(user, keyID)
+	if !ok {
+		return nil, errors.New("KeyDirectoryService Lookup | Unknown key id")
+	}
+
+	return key.Key.Public(), nil
+}
+
+func (s *KeyDirectoryService) LookupAt(username, keyID string, signedAt time.Time) (crypto.PublicKey, error) {
+	user, err := s.Users.Get(username)
+	if err != nil {
+		return nil, errors.New("KeyDirectoryService LookupAt | Unknown user")
+	}
+
+	key, ok := findUserKey(user, keyID)
+	if !ok {
+		return nil, errors.New("KeyDirectoryService LookupAt | Unknown key id")
+	}
+
+	if !key.ValidAt(signedAt) {
+		return nil, errors.New("KeyDirectoryService LookupAt | Key was revoked (" + string(key.Reason) + ") before the signature was made")
+	}
+
+	return key.Key.Public(), nil
+}
+
+// Certificate returns the certificate of the given key, issuing it on first
+// request. The certificate of a revoked key is dropped from the cache.
+func (s *KeyDirectoryService) Certificate(username, keyID string) (*x509.Certificate, error) {
+	if s.Authority == nil {
+		return nil, errors.New("KeyDirectoryService Certificate | No certificate authority")
+	}
+
+	publicKey, err := s.LookupAt(username, keyID, time.Now())
+	if err != nil {
+		s.Evict(username, keyID)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	certificate, ok := s.certificates[username+"/"+keyID]
+	if ok {
+		return certificate, nil
+	}
+
+	certificate, err = s.Authority.Issue(username, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s.certificates[username+"/"+keyID] = certificate
+	return certificate, nil
+}
+
+// Evict drops the cached certificate of the given key, the key service calls
+// it when the key is revoked.
+func (s *KeyDirectoryService) Evict(username, keyID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.certificates, username+"/"+keyID)
+}
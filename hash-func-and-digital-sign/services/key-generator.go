@@ -0,0 +1,67 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+)
+
+// DefaultKeyAlgorithm is used for signing keys unless configured otherwise.
+const DefaultKeyAlgorithm = domain.KeyRSA2048
+
+// KeyGenerator generates a signing key of Algorithm on every call.
+type KeyGenerator struct {
+	Algorithm string
+}
+
+func NewKeyGenerator(algorithm string) (interfaces.IKeyGenerator, error) {
+	if _, err := generatorFor(algorithm); err != nil {
+		return nil, err
+	}
+	return &KeyGenerator{Algorithm: algorithm}, nil
+}
+
+func (g *KeyGenerator) Generate() (crypto.Signer, error) {
+	generate, err := generatorFor(g.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return generate()
+}
+
+func generatorFor(algorithm string) (func() (crypto.Signer, error), error) {
+	rsaKey := func(bits int) func() (crypto.Signer, error) {
+		return func() (crypto.Signer, error) {
+			return rsa.GenerateKey(rand.Reader, bits)
+		}
+	}
+	ecKey := func(curve elliptic.Curve) func() (crypto.Signer, error) {
+		return func() (crypto.Signer, error) {
+			return ecdsa.GenerateKey(curve, rand.Reader)
+		}
+	}
+
+	switch algorithm {
+	case domain.KeyRSA2048:
+		return rsaKey(2048), nil
+	case domain.KeyRSA3072:
+		return rsaKey(3072), nil
+	case domain.KeyRSA4096:
+		return rsaKey(4096), nil
+	case domain.KeyECDSAP256:
+		return ecKey(elliptic.P256()), nil
+	case domain.KeyECDSAP384:
+		return ecKey(elliptic.P384()), nil
+	}
+	return nil, fmt.Errorf("KeyGenerator | unsupported key algorithm %q", algorithm)
+}
+
+func defaultKeyGenerator() interfaces.IKeyGenerator {
+	return &KeyGenerator{Algorithm: DefaultKeyAlgorithm}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"crypto"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+)
+
+// KeyPool keeps up to Size pre-generated keys, refilled in the background
+// by a pool of workers, so taking a key does not wait for a slow RSA
+// generation. When the pool runs dry Generate falls back to generating
+// the key itself.
+type KeyPool struct {
+	generator interfaces.IKeyGenerator
+	workers   int
+	keys      chan crypto.Signer
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+const (
+	// DefaultKeyPoolSize and DefaultKeyPoolWorkers size the pool the default
+	// user and key services draw from.
+	DefaultKeyPoolSize    = 16
+	DefaultKeyPoolWorkers = 2
+
+	keyPoolMinBackoff = 100 * time.Millisecond
+	keyPoolMaxBackoff = 30 * time.Second
+)
+
+var (
+	sharedKeyPoolOnce sync.Once
+	sharedKeyPool     *KeyPool
+)
+
+// defaultKeyPool returns the process wide pool of default keys. Its
+// workers only start on the first Generate, so services that never
+// create a key, like a login, do not pay for them.
+func defaultKeyPool() interfaces.IKeyGenerator {
+	return sharedDefaultKeyPool()
+}
+
+func sharedDefaultKeyPool() *KeyPool {
+	sharedKeyPoolOnce.Do(func() {
+		sharedKeyPool, _ = NewLazyKeyPool(defaultKeyGenerator(), DefaultKeyPoolSize, DefaultKeyPoolWorkers)
+	})
+	return sharedKeyPool
+}
+
+// CloseDefaultKeyPool stops the workers of the pool the default services
+// share. Keys are still generated afterwards, just not ahead of time.
+func CloseDefaultKeyPool() {
+	sharedDefaultKeyPool().Close()
+}
+
+func NewKeyPool(generator interfaces.IKeyGenerator, size, workers int) (*KeyPool, error) {
+	pool, err := NewLazyKeyPool(generator, size, workers)
+	if err != nil {
+		return nil, err
+	}
+
+	pool.start()
+	return pool, nil
+}
+
+// NewLazyKeyPool is like NewKeyPool but its workers wait for the first
+// Generate before they start filling the pool.
+func NewLazyKeyPool(generator interfaces.IKeyGenerator, size, workers int) (*KeyPool, error) {
+	if size < 1 || workers < 1 {
+		return nil, errors.New("KeyPool | Size and workers must be positive")
+	}
+
+	return &KeyPool{
+		generator: generator,
+		workers:   workers,
+		keys:      make(chan crypto.Signer, size),
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+func (p *KeyPool) start() {
+	p.startOnce.Do(func() {
+		for i := 0; i < p.workers; i++ {
+			p.wg.Add(1)
+			go p.work()
+		}
+	})
+}
+
+func (p *KeyPool) Generate() (crypto.Signer, error) {
+	p.start()
+	select {
+	case key := <-p.keys:
+		return key, nil
+	default:
+		return p.generator.Generate()
+	}
+}
+
+// Available returns the number of keys ready to be taken.
+func (p *KeyPool) Available() int {
+	return len(p.keys)
+}
+
+// Close stops the workers and waits for them to exit.
+func (p *KeyPool) Close() {
+	p.closeOnce.Do(func() {
+		// a pool closed before its first Generate never starts
+		p.startOnce.Do(func() {})
+		close(p.stop)
+		p.wg.Wait()
+	})
+}
+
+func (p *KeyPool) work() {
+	defer p.wg.Done()
+
+	backoff := keyPoolMinBackoff
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		// Generate falls back to the generator meanwhile, so a failure only
+		// slows the refill down
+		key, err := p.generator.Generate()
+		if err != nil {
+			log.Printf("KeyPool | key generation failed, retrying in %s: %s", backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-p.stop:
+				return
+			}
+			if backoff *= 2; backoff > keyPoolMaxBackoff {
+				backoff = keyPoolMaxBackoff
+			}
+			continue
+		}
+		backoff = keyPoolMinBackoff
+
+		select {
+		case p.keys <- key:
+		case <-p.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"crypto"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/keystore"
+)
+
+// SealingKeyGenerator seals every key Keys generates, so the plaintext
+// private key of a new user never leaves Generate.
+type SealingKeyGenerator struct {
+	Keys    interfaces.IKeyGenerator
+	Keyring *keystore.Keyring
+}
+
+func NewSealingKeyGenerator(keys interfaces.IKeyGenerator, keyring *keystore.Keyring) interfaces.IKeyGenerator {
+	return &SealingKeyGenerator{Keys: keys, Keyring: keyring}
+}
+
+func (g *SealingKeyGenerator) Generate() (crypto.Signer, error) {
+	key, err := g.Keys.Generate()
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Seal(g.Keyring, key)
+}
+
+// RewrapKeys rewraps the data keys of every sealed key in db with the
+// current master key of keyring, which must still hold the previous master
+// keys. Plaintext keys are sealed on the way. It returns the number of users
+// rewritten; once it succeeds the previous master keys can be removed.
+func RewrapKeys(db interfaces.IDatabase, keyring *keystore.Keyring) (int, error) {
+	users, err := db.List(domain.UserFilter{}, domain.Pagination{})
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	for _, listed := range users {
+		err := db.WithTx(func(tx interfaces.ITransaction) error {
+			user, err := tx.Get(listed.Username)
+			if err != nil {
+				return err
+			}
+
+			keys := userKeys(user)
+			for i := range keys {
+				var rewrapped *keystore.SealedKey
+				if sealed, ok := keys[i].Key.(*keystore.SealedKey); ok {
+					rewrapped, err = sealed.Rewrap(keyring)
+				} else {
+					rewrapped, err = keystore.Seal(keyring, keys[i].Key)
+				}
+				if err != nil {
+					return err
+				}
+
+				if keys[i].Status == domain.KeyActive {
+					user.Key = rewrapped
+				}
+				keys[i].Key = rewrapped
+			}
+
+			user.Keys = keys
+			return tx.CompareAndSet(user.Username, user)
+		})
+		if err != nil {
+			return rewritten, err
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// KeyService rotates and revokes users' signing keys. Old keys are never
+// deleted so signatures made with them can still be checked.
+type KeyService struct {
+	Users interfaces.IDatabase
+	Keys  interfaces.IKeyGenerator
+	// Directory, when set, drops the cached certificates of revoked keys.
+	Directory interfaces.IKeyDirectory
+}
+
+func NewKeyService(db interfaces.IDatabase) interfaces.IKeyService {
+	return NewKeyServiceWithKeys(db, defaultKeyPool())
+}
+
+func NewKeyServiceWithKeys(db interfaces.IDatabase, keys interfaces.IKeyGenerator) interfaces.IKeyService {
+	return &KeyService{Users: db, Keys: keys}
+}
+
+func NewKeyServiceWithDirectory(db interfaces.IDatabase, directory interfaces.IKeyDirectory) interfaces.IKeyService {
+	return &KeyService{Users: db, Keys: defaultKeyPool(), Directory: directory}
+}
+
+// Rotate retires the active key, if any, and makes a new one active.
+func (s *KeyService) Rotate(username string) (domain.SigningKey, error) {
+	key, err := newSigningKey(s.Keys)
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+
+	err = s.Users.WithTx(func(tx interfaces.ITransaction) error {
+		user, err := tx.Get(username)
+		if err != nil {
+			return errors.New("KeyService Rotate | User not found")
+		}
+
+		keys := userKeys(user)
+		for i := range keys {
+			if keys[i].Status == domain.KeyActive {
+				keys[i].Status = domain.KeyRetired
+			}
+		}
+
+		user.Keys = append(keys, key)
+		user.Key = key.Key
+		return tx.CompareAndSet(username, user)
+	})
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+
+	return key, nil
+}
+
+// Revoke marks the key as revoked from now on. Revoking the active key
+// leaves the user without one until the next Rotate.
+func (s *KeyService) Revoke(username, keyID string, reason domain.RevocationReason) error {
+	if reason == "" {
+		reason = domain.ReasonUnspecified
+	}
+
+	err := s.Users.WithTx(func(tx interfaces.ITransaction) error {
+		user, err := tx.Get(username)
+		if err != nil {
+			return errors.New("KeyService Revoke | User not found")
+		}
+
+		keys := userKeys(user)
+		found := false
+		for i := range keys {
+			if keys[i].ID != keyID {
+				continue
+			}
+			if keys[i].Status == domain.KeyRevoked {
+				return errors.New("KeyService Revoke | Key is already revoked")
+			}
+			if keys[i].Status == domain.KeyActive {
+				user.Key = nil
+			}
+			keys[i].Status = domain.KeyRevoked
+			keys[i].RevokedAt = time.Now().UTC()
+			keys[i].Reason = reason
+			found = true
+		}
+		if !found {
+			return errors.New("KeyService Revoke | Key not found")
+		}
+
+		user.Keys = keys
+		return tx.CompareAndSet(username, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.Directory != nil {
+		s.Directory.Evict(username, keyID)
+	}
+	return nil
+}
+
+// RevocationList returns every revoked key of every user.
+func (s *KeyService) RevocationList() ([]domain.Revocation, error) {
+	users, err := s.Users.List(domain.UserFilter{}, domain.Pagination{})
+	if err != nil {
+		return nil, err
+	}
+
+	revocations := []domain.Revocation{}
+	for _, user := range users {
+		for _, key := range user.Keys {
+			if key.Status == domain.KeyRevoked {
+				revocations = append(revocations, domain.Revocation{
+					Username:  user.Username,
+					KeyID:     key.ID,
+					Reason:    key.Reason,
+					RevokedAt: key.RevokedAt,
+				})
+			}
+		}
+	}
+	return revocations, nil
+}
+
+func newSigningKey(keys interfaces.IKeyGenerator) (domain.SigningKey, error) {
+	key, err := keys.Generate()
+	if err != nil {
+		return domain.SigningKey{}, err
+	}
+
+	return domain.SigningKey{
+		ID:      utils.GetKeyID(key.Public()),
+		Key:     key,
+		Created: time.Now().UTC(),
+		Status:  domain.KeyActive,
+	}, nil
+}
+
+// userKeys returns a copy of the user's keys, users stored before key
+// rotation existed only have Key, which is returned as the active key.
+func userKeys(user domain.User) []domain.SigningKey {
+	if len(user.Keys) == 0 && user.Key != nil {
+		return []domain.SigningKey{{
+			ID:     utils.GetKeyID(user.Key.Public()),
+			Key:    user.Key,
+			Status: domain.KeyActive,
+		}}
+	}
+
+	return append([]domain.SigningKey(nil), user.Keys...)
+}
+
+func findUserKey(user domain.User, keyID string) (domain.SigningKey, bool) {
+	return domain.User{Keys: userKeys(user)}.FindKey(keyID)
+}
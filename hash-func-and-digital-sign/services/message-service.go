@@ -2,37 +2,104 @@ package services
 
 import (
 	"crypto"
-	"crypto/rand"
-	"crypto/rsa"
 	"errors"
+	"time"
 
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
 )
 
-type MessageService struct{}
+type MessageService struct {
+	// HashAlgorithm is used for new messages, existing ones are checked
+	// with the algorithm recorded in them.
+	HashAlgorithm string
+	// Timestamps, if set, countersigns every new message and is required to
+	// have countersigned every checked one.
+	Timestamps interfaces.ITimestampAuthority
+	// Log, if set, gets every new message appended.
+	Log interfaces.IAuditLog
+}
 
 func NewMessageService() interfaces.IMessageService {
-	return &MessageService{}
+	return &MessageService{HashAlgorithm: domain.HashSHA256}
+}
+
+func NewMessageServiceWithHash(hashAlgorithm string) (interfaces.IMessageService, error) {
+	if _, err := utils.LookupHash(hashAlgorithm); err != nil {
+		return nil, err
+	}
+	return &MessageService{HashAlgorithm: hashAlgorithm}, nil
+}
+
+func NewTimestampedMessageService(timestamps interfaces.ITimestampAuthority) interfaces.IMessageService {
+	return &MessageService{HashAlgorithm: domain.HashSHA256, Timestamps: timestamps}
 }
 
-func (s *MessageService) NewMessage(from domain.User, message string) (hashedMessage []byte, signature []byte, err error) {
-	hashedMessage = utils.GetSHA256Digest(message)
+func NewAuditedMessageService(log interfaces.IAuditLog) interfaces.IMessageService {
+	return &MessageService{HashAlgorithm: domain.HashSHA256, Log: log}
+}
+
+func (s *MessageService) NewMessage(from domain.User, message string) (domain.SignedMessage, error) {
+	if from.Key == nil {
+		return domain.SignedMessage{}, errors.New("MessageService | User has no active signing key")
+	}
+
+	scheme, err := defaultScheme(from.Key.Public(), s.HashAlgorithm)
+	if err != nil {
+		return domain.SignedMessage{}, err
+	}
 
-	signature, err = rsa.SignPKCS1v15(rand.Reader, from.Key, crypto.SHA256, hashedMessage)
+	signed := domain.SignedMessage{
+		Payload:         []byte(message),
+		Signer:          from.Username,
+		KeyID:           utils.GetKeyID(from.Key.Public()),
+		HashAlgorithm:   s.HashAlgorithm,
+		SignatureScheme: scheme,
+		Timestamp:       time.Now().UTC(),
+	}
 
+	signature, err := signInput(from.Key, s.HashAlgorithm, scheme, signed.SigningInput())
 	if err != nil {
-		return nil, nil, errors.New("MessageService | Could not create new message")
+		return domain.SignedMessage{}, errors.New("MessageService | Could not create new message")
+	}
+
+	signed.Signature = signature
+
+	if s.Timestamps != nil {
+		token, err := timestampSignature(s.Timestamps, s.HashAlgorithm, signature)
+		if err != nil {
+			return domain.SignedMessage{}, err
+		}
+		signed.TimestampToken = &token
 	}
 
-	return hashedMessage, signature, nil
+	if s.Log != nil {
+		if _, err := s.Log.Append(signed); err != nil {
+			return domain.SignedMessage{}, err
+		}
+	}
+
+	return signed, nil
 }
 
-func (s *MessageService) CheckMessage(publicKey *rsa.PublicKey, hashedMessage, signature []byte) error {
-	err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, []byte(hashedMessage), []byte(signature))
+// CheckMessage recomputes the hash of the whole envelope and verifies the
+// signature with publicKey, which must be the key the envelope names.
+func (s *MessageService) CheckMessage(publicKey crypto.PublicKey, message domain.SignedMessage) error {
+	if message.KeyID != utils.GetKeyID(publicKey) {
+		return errors.New("MessageService | Message was signed with another key")
+	}
+
+	err := verifyInput(publicKey, message.HashAlgorithm, message.SignatureScheme, message.SigningInput(), message.Signature)
 	if err != nil {
 		return errors.New("MessageService | Message signature check failed")
 	}
+
+	if s.Timestamps != nil {
+		if message.TimestampToken == nil {
+			return errors.New("MessageService | Message has no timestamp token")
+		}
+		return checkSignatureTimestamp(s.Timestamps, *message.TimestampToken, message.Signature)
+	}
 	return nil
 }
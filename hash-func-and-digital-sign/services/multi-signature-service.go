@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// MultiSignatureService runs the co-signing workflow: a document is created
+// with an m-of-n policy, passed to the listed signers to CoSign and accepted
+// by CheckDocument once enough of them signed.
+type MultiSignatureService struct {
+	// Directory provides the signers' public keys.
+	Directory     interfaces.IKeyDirectory
+	HashAlgorithm string
+}
+
+func NewMultiSignatureService(directory interfaces.IKeyDirectory) interfaces.IMultiSignatureService {
+	return &MultiSignatureService{Directory: directory, HashAlgorithm: domain.HashSHA256}
+}
+
+func (s *MultiSignatureService) NewDocument(payload string, policy domain.SignaturePolicy) (domain.MultiSignedMessage, error) {
+	if err := validatePolicy(policy); err != nil {
+		return domain.MultiSignedMessage{}, err
+	}
+
+	return domain.MultiSignedMessage{
+		Payload: []byte(payload),
+		Policy: domain.SignaturePolicy{
+			Signers:   append([]string(nil), policy.Signers...),
+			Threshold: policy.Threshold,
+		},
+		Created:    time.Now().UTC(),
+		Signatures: []domain.CoSignature{},
+	}, nil
+}
+
+// CoSign returns document with the signature of from added.
+func (s *MultiSignatureService) CoSign(from domain.User, document domain.MultiSignedMessage) (domain.MultiSignedMessage, error) {
+	if !isListedSigner(document.Policy, from.Username) {
+		return domain.MultiSignedMessage{}, errors.New("MultiSignatureService CoSign | User is not a listed signer")
+	}
+	if document.HasSigned(from.Username) {
+		return domain.MultiSignedMessage{}, errors.New("MultiSignatureService CoSign | User already signed")
+	}
+	if from.Key == nil {
+		return domain.MultiSignedMessage{}, errors.New("MultiSignatureService CoSign | User has no active signing key")
+	}
+
+	scheme, err := defaultScheme(from.Key.Public(), s.HashAlgorithm)
+	if err != nil {
+		return domain.MultiSignedMessage{}, err
+	}
+
+	signature := domain.CoSignature{
+		Signer:          from.Username,
+		KeyID:           utils.GetKeyID(from.Key.Public()),
+		HashAlgorithm:   s.HashAlgorithm,
+		SignatureScheme: scheme,
+		Timestamp:       time.Now().UTC(),
+	}
+
+	signature.Signature, err = signInput(from.Key, s.HashAlgorithm, scheme, document.SigningInput(signature))
+	if err != nil {
+		return domain.MultiSignedMessage{}, errors.New("MultiSignatureService CoSign | Could not sign document")
+	}
+
+	signed := document
+	signed.Signatures = append(append([]domain.CoSignature(nil), document.Signatures...), signature)
+	return signed, nil
+}
+
+// CheckDocument verifies every signature and fails if any is invalid or
+// fewer than the policy threshold of listed signers signed.
+func (s *MultiSignatureService) CheckDocument(document domain.MultiSignedMessage) error {
+	if err := validatePolicy(document.Policy); err != nil {
+		return err
+	}
+
+	signed := make(map[string]bool)
+	for _, signature := range document.Signatures {
+		if !isListedSigner(document.Policy, signature.Signer) {
+			return fmt.Errorf("MultiSignatureService CheckDocument | %s is not a listed signer", signature.Signer)
+		}
+		if signed[signature.Signer] {
+			return fmt.Errorf("MultiSignatureService CheckDocument | %s signed more than once", signature.Signer)
+		}
+
+		publicKey, err := s.Directory.LookupAt(signature.Signer, signature.KeyID, signature.Timestamp)
+		if err != nil {
+			return err
+		}
+
+		err = verifyInput(publicKey, signature.HashAlgorithm, signature.SignatureScheme, document.SigningInput(signature), signature.Signature)
+		if err != nil {
+			return fmt.Errorf("MultiSignatureService CheckDocument | Signature of %s check failed", signature.Signer)
+		}
+		signed[signature.Signer] = true
+	}
+
+	if len(signed) < document.Policy.Threshold {
+		return fmt.Errorf("MultiSignatureService CheckDocument | %d of %d required signatures, missing %s",
+			len(signed), document.Policy.Threshold, strings.Join(document.Missing(), ", "))
+	}
+	return nil
+}
+
+func validatePolicy(policy domain.SignaturePolicy) error {
+	seen := make(map[string]bool)
+	for _, signer := range policy.Signers {
+		if signer == "" || seen[signer] {
+			return errors.New("MultiSignatureService | Signers must be unique and not empty")
+		}
+		seen[signer] = true
+	}
+
+	if policy.Threshold < 1 || policy.Threshold > len(policy.Signers) {
+		return errors.New("MultiSignatureService | Threshold must be between 1 and the number of signers")
+	}
+	return nil
+}
+
+func isListedSigner(policy domain.SignaturePolicy, username string) bool {
+	for _, signer := range policy.Signers {
+		if signer == username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"crypto"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// defaultScheme returns the signature scheme new signatures with publicKey
+// and hashAlgorithm use. The scheme is part of the signed input, so callers
+// pick it before signing.
+func defaultScheme(publicKey crypto.PublicKey, hashAlgorithm string) (string, error) {
+	hash, err := utils.LookupHash(hashAlgorithm)
+	if err != nil {
+		return "", err
+	}
+	return utils.DefaultScheme(publicKey, hash), nil
+}
+
+// signInput hashes input with hashAlgorithm and signs the digest with scheme.
+func signInput(key crypto.Signer, hashAlgorithm, scheme string, input []byte) ([]byte, error) {
+	hash, err := utils.LookupHash(hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := utils.GetDigest(hashAlgorithm, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.SignDigest(key, scheme, hash, digest)
+}
+
+// verifyInput checks a signature made by signInput, using the algorithm and
+// scheme recorded with it rather than the current defaults.
+func verifyInput(publicKey crypto.PublicKey, hashAlgorithm, scheme string, input, signature []byte) error {
+	hash, err := utils.LookupHash(hashAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	digest, err := utils.GetDigest(hashAlgorithm, input)
+	if err != nil {
+		return err
+	}
+
+	return utils.VerifyDigest(publicKey, scheme, hash, digest, signature)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+// TimestampAuthority is a local timestamp authority in the spirit of
+// RFC 3161: it countersigns a digest together with the current time, so
+// anyone trusting its key can tell that the digest existed at that time.
+type TimestampAuthority struct {
+	Name string
+
+	key    *rsa.PrivateKey
+	mu     sync.Mutex
+	serial uint64
+}
+
+func NewTimestampAuthority(name string) (interfaces.ITimestampAuthority, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampAuthority{Name: name, key: key}, nil
+}
+
+func (a *TimestampAuthority) PublicKey() *rsa.PublicKey {
+	return &a.key.PublicKey
+}
+
+// Timestamp issues a token over digest, which must have been made with
+// hashAlgorithm. The token is signed with the same algorithm.
+func (a *TimestampAuthority) Timestamp(hashAlgorithm string, digest []byte) (domain.TimestampToken, error) {
+	hash, err := utils.LookupHash(hashAlgorithm)
+	if err != nil {
+		return domain.TimestampToken{}, err
+	}
+	if len(digest) != hash.Size() {
+		return domain.TimestampToken{}, errors.New("TimestampAuthority Timestamp | Digest length does not match the hash algorithm")
+	}
+
+	scheme, err := defaultScheme(&a.key.PublicKey, hashAlgorithm)
+	if err != nil {
+		return domain.TimestampToken{}, err
+	}
+
+	a.mu.Lock()
+	a.serial++
+	serial := a.serial
+	a.mu.Unlock()
+
+	token := domain.TimestampToken{
+		Version:         domain.TimestampTokenV1,
+		Authority:       a.Name,
+		KeyID:           utils.GetKeyID(&a.key.PublicKey),
+		HashAlgorithm:   hashAlgorithm,
+		SignatureScheme: scheme,
+		MessageImprint:  append([]byte(nil), digest...),
+		Serial:          serial,
+		Time:            time.Now().UTC(),
+	}
+
+	token.Signature, err = signInput(a.key, hashAlgorithm, scheme, token.SigningInput())
+	if err != nil {
+		return domain.TimestampToken{}, errors.New("TimestampAuthority Timestamp | Could not sign token")
+	}
+	return token, nil
+}
+
+func (a *TimestampAuthority) Verify(token domain.TimestampToken, digest []byte) error {
+	return VerifyTimestampToken(&a.key.PublicKey, token, digest)
+}
+
+// VerifyTimestampToken checks that token was issued by the authority owning
+// publicKey for digest.
+func VerifyTimestampToken(publicKey *rsa.PublicKey, token domain.TimestampToken, digest []byte) error {
+	if token.Version != domain.TimestampTokenV1 {
+		return errors.New("TimestampToken | Unsupported token version")
+	}
+	if token.KeyID != utils.GetKeyID(publicKey) {
+		return errors.New("TimestampToken | Token was issued by another authority")
+	}
+	if !bytes.Equal(token.MessageImprint, digest) {
+		return errors.New("TimestampToken | Token was issued for another digest")
+	}
+
+	if err := verifyInput(publicKey, token.HashAlgorithm, token.SignatureScheme, token.SigningInput(), token.Signature); err != nil {
+		return errors.New("TimestampToken | Token signature check failed")
+	}
+	return nil
+}
+
+// timestampSignature gets a token over the digest of a signature, like the
+// signature timestamp attribute of CMS.
+func timestampSignature(authority interfaces.ITimestampAuthority, hashAlgorithm string, signature []byte) (domain.TimestampToken, error) {
+	digest, err := utils.GetDigest(hashAlgorithm, signature)
+	if err != nil {
+		return domain.TimestampToken{}, err
+	}
+	return authority.Timestamp(hashAlgorithm, digest)
+}
+
+func checkSignatureTimestamp(authority interfaces.ITimestampAuthority, token domain.TimestampToken, signature []byte) error {
+	digest, err := utils.GetDigest(token.HashAlgorithm, signature)
+	if err != nil {
+		return err
+	}
+	return authority.Verify(token, digest)
+}
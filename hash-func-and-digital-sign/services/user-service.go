@@ -1,22 +1,49 @@
 package services
 
 import (
-	"bytes"
 	"crypto/rand"
-	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
 	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
-	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/passwords"
 )
 
+// PasswordResetTTL is how long a password reset token stays valid.
+const PasswordResetTTL = 15 * time.Minute
+
 type UserService struct {
-	Users interfaces.IDatabase
+	Users  interfaces.IDatabase
+	Hasher *passwords.Hasher
+	// Keys generates the signing keys of new users, a KeyPool keeps
+	// registration from waiting for RSA key generation.
+	Keys interfaces.IKeyGenerator
+
+	resetMu sync.Mutex
+	resets  map[string]passwordReset
+}
+
+// passwordReset keeps only the digest of the token handed out.
+type passwordReset struct {
+	digest  [sha256.Size]byte
+	expires time.Time
 }
 
 func NewUserService(db interfaces.IDatabase) interfaces.IUserService {
-	return &UserService{Users: db}
+	return NewUserServiceWithHasher(db, passwords.NewHasher())
+}
+
+func NewUserServiceWithHasher(db interfaces.IDatabase, hasher *passwords.Hasher) interfaces.IUserService {
+	return &UserService{Users: db, Hasher: hasher, Keys: defaultKeyPool(), resets: make(map[string]passwordReset)}
+}
+
+func NewUserServiceWithKeys(db interfaces.IDatabase, keys interfaces.IKeyGenerator) interfaces.IUserService {
+	return &UserService{Users: db, Hasher: passwords.NewHasher(), Keys: keys, resets: make(map[string]passwordReset)}
 }
 
 func (s *UserService) Register(username, password string) error {
@@ -26,20 +53,30 @@ func (s *UserService) Register(username, password string) error {
 		return errors.New("UserService Register | User already exists")
 	}
 
-	hashedPassword := utils.GetSHA256Digest(password)
+	hashedPassword, err := s.Hasher.Hash(password)
+	if err != nil {
+		return err
+	}
 
-	key, err := rsa.GenerateKey(rand.Reader, 1028)
+	key, err := newSigningKey(s.Keys)
 	if err != nil {
 		return err
 	}
 
 	user := domain.User{
 		Username: username,
-		Password: hashedPassword,
-		Key:      key,
+		Password: []byte(hashedPassword),
+		Key:      key.Key,
+		Keys:     []domain.SigningKey{key},
+	}
+
+	// version 0 only inserts if no concurrent Register won the race
+	err = s.Users.CompareAndSet(username, user)
+	if errors.Is(err, interfaces.ErrVersionConflict) {
+		return errors.New("UserService Register | User already exists")
 	}
 
-	return s.Users.Set(username, user)
+	return err
 }
 
 func (s *UserService) Login(username, password string) (domain.User, error) {
@@ -48,11 +85,121 @@ func (s *UserService) Login(username, password string) (domain.User, error) {
 		return domain.User{}, err
 	}
 
-	hashedPassword := utils.GetSHA256Digest(password)
-
-	if !bytes.Equal(hashedPassword, user.Password) {
+	needsRehash, err := s.Hasher.Verify(password, string(user.Password))
+	if err != nil {
 		return domain.User{}, errors.New("UserService Login | Invalid password")
 	}
 
+	if needsRehash {
+		hashedPassword, err := s.Hasher.Hash(password)
+		if err != nil {
+			return domain.User{}, err
+		}
+
+		// a conflict means the user changed meanwhile, keep that version
+		user.Password = []byte(hashedPassword)
+		err = s.Users.CompareAndSet(username, user)
+		if err != nil && !errors.Is(err, interfaces.ErrVersionConflict) {
+			return domain.User{}, err
+		}
+		if err == nil {
+			user.Version++
+		}
+	}
+
 	return user, nil
 }
+
+func (s *UserService) ChangePassword(username, oldPassword, newPassword string) error {
+	user, err := s.Users.Get(username)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.Hasher.Verify(oldPassword, string(user.Password)); err != nil {
+		return errors.New("UserService ChangePassword | Invalid password")
+	}
+
+	return s.setPassword(user, newPassword)
+}
+
+func (s *UserService) RequestPasswordReset(username string) (string, error) {
+	if _, err := s.Users.Get(username); err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	s.resetMu.Lock()
+	defer s.resetMu.Unlock()
+
+	if s.resets == nil {
+		s.resets = make(map[string]passwordReset)
+	}
+	s.resets[username] = passwordReset{digest: sha256.Sum256([]byte(token)), expires: time.Now().Add(PasswordResetTTL)}
+	return token, nil
+}
+
+func (s *UserService) ResetPassword(username, token, newPassword string) error {
+	digest := sha256.Sum256([]byte(token))
+
+	s.resetMu.Lock()
+	reset, ok := s.resets[username]
+	valid := ok && time.Now().Before(reset.expires) && subtle.ConstantTimeCompare(digest[:], reset.digest[:]) == 1
+	if valid {
+		delete(s.resets, username)
+	}
+	s.resetMu.Unlock()
+
+	if !valid {
+		return errors.New("UserService ResetPassword | Reset token is invalid or expired")
+	}
+
+	user, err := s.Users.Get(username)
+	if err != nil {
+		return err
+	}
+	return s.setPassword(user, newPassword)
+}
+
+// Delete removes the account, its signing keys included, after checking the
+// password.
+func (s *UserService) Delete(username, password string) error {
+	user, err := s.Users.Get(username)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.Hasher.Verify(password, string(user.Password)); err != nil {
+		return errors.New("UserService Delete | Invalid password")
+	}
+
+	s.resetMu.Lock()
+	delete(s.resets, username)
+	s.resetMu.Unlock()
+
+	return s.Users.Delete(username)
+}
+
+// setPassword stores the hash of password unless user changed meanwhile.
+func (s *UserService) setPassword(user domain.User, password string) error {
+	if password == "" {
+		return errors.New("UserService | Password must not be empty")
+	}
+
+	hashedPassword, err := s.Hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = []byte(hashedPassword)
+	err = s.Users.CompareAndSet(user.Username, user)
+	if errors.Is(err, interfaces.ErrVersionConflict) {
+		return errors.New("UserService | User changed meanwhile, try again")
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/passwords"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+func newAccount(t *testing.T) (interfaces.IUserService, interfaces.IDatabase) {
+	t.Helper()
+	db := database.NewDatabase()
+	userService := services.NewUserServiceWithHasher(db, fastHasher(passwords.Argon2id))
+	if err := userService.Register("darkcat", "villv013"); err != nil {
+		t.Fatal(err)
+	}
+	return userService, db
+}
+
+func TestChangePassword(t *testing.T) {
+	//Arrange
+	userService, _ := newAccount(t)
+
+	//Act
+	wrongErr := userService.ChangePassword("darkcat", "wrong", "newPass")
+	err := userService.ChangePassword("darkcat", "villv013", "newPass")
+	_, oldErr := userService.Login("darkcat", "villv013")
+	_, newErr := userService.Login("darkcat", "newPass")
+
+	//Assert
+	if wrongErr == nil {
+		t.Errorf("ChangePassword error with a wrong password should not be nil")
+	}
+	if err != nil {
+		t.Fatalf("ChangePassword error should be nil, got %s", err.Error())
+	}
+	if oldErr == nil {
+		t.Errorf("Login error with the old password should not be nil")
+	}
+	if newErr != nil {
+		t.Errorf("Login error with the new password should be nil, got %s", newErr.Error())
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	//Arrange
+	userService, _ := newAccount(t)
+	token, err := userService.RequestPasswordReset("darkcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	wrongErr := userService.ResetPassword("darkcat", token+"x", "newPass")
+	resetErr := userService.ResetPassword("darkcat", token, "newPass")
+	reuseErr := userService.ResetPassword("darkcat", token, "otherPass")
+	_, loginErr := userService.Login("darkcat", "newPass")
+	_, unknownErr := userService.RequestPasswordReset("nobody")
+
+	//Assert
+	if wrongErr == nil {
+		t.Errorf("ResetPassword error with a wrong token should not be nil")
+	}
+	if resetErr != nil {
+		t.Fatalf("ResetPassword error should be nil, got %s", resetErr.Error())
+	}
+	if reuseErr == nil {
+		t.Errorf("ResetPassword error with a used token should not be nil")
+	}
+	if loginErr != nil {
+		t.Errorf("Login error with the reset password should be nil, got %s", loginErr.Error())
+	}
+	if unknownErr == nil {
+		t.Errorf("RequestPasswordReset error for an unknown user should not be nil")
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	//Arrange
+	userService, db := newAccount(t)
+
+	//Act
+	wrongErr := userService.Delete("darkcat", "wrong")
+	err := userService.Delete("darkcat", "villv013")
+	_, getErr := db.Get("darkcat")
+	_, loginErr := userService.Login("darkcat", "villv013")
+
+	//Assert
+	if wrongErr == nil {
+		t.Errorf("Delete error with a wrong password should not be nil")
+	}
+	if err != nil {
+		t.Fatalf("Delete error should be nil, got %s", err.Error())
+	}
+	if getErr == nil || loginErr == nil {
+		t.Errorf("Deleted user should not be found")
+	}
+}
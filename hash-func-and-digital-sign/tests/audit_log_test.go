@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/merkle"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+func newLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = merkle.LeafHash([]byte(fmt.Sprintf("entry %d", i)))
+	}
+	return leaves
+}
+
+func TestMerkleInclusionProofs(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		leaves := newLeaves(size)
+		root := merkle.RootHash(leaves)
+
+		for index := 0; index < size; index++ {
+			//Act
+			proof, err := merkle.InclusionProof(leaves, index)
+
+			//Assert
+			if err != nil {
+				t.Fatalf("InclusionProof error should be nil, got %s", err.Error())
+			}
+			if err := merkle.VerifyInclusion(uint64(index), uint64(size), leaves[index], proof, root); err != nil {
+				t.Errorf("size %d index %d: VerifyInclusion error should be nil, got %s", size, index, err.Error())
+			}
+			wrong := (index + 1) % size
+			if size > 1 && merkle.VerifyInclusion(uint64(index), uint64(size), leaves[wrong], proof, root) == nil {
+				t.Errorf("size %d index %d: VerifyInclusion error for another leaf should not be nil", size, index)
+			}
+		}
+	}
+}
+
+func TestMerkleConsistencyProofs(t *testing.T) {
+	leaves := newLeaves(17)
+
+	for second := 1; second <= len(leaves); second++ {
+		for first := 1; first <= second; first++ {
+			firstRoot := merkle.RootHash(leaves[:first])
+			secondRoot := merkle.RootHash(leaves[:second])
+
+			//Act
+			proof, err := merkle.ConsistencyProof(leaves[:second], first)
+
+			//Assert
+			if err != nil {
+				t.Fatalf("ConsistencyProof error should be nil, got %s", err.Error())
+			}
+			if err := merkle.VerifyConsistency(uint64(first), uint64(second), firstRoot, secondRoot, proof); err != nil {
+				t.Errorf("%d to %d: VerifyConsistency error should be nil, got %s", first, second, err.Error())
+			}
+
+			altered := newLeaves(first)
+			altered[first-1] = merkle.LeafHash([]byte("altered"))
+			if first < second && merkle.VerifyConsistency(uint64(first), uint64(second), merkle.RootHash(altered), secondRoot, proof) == nil {
+				t.Errorf("%d to %d: VerifyConsistency error for an altered tree should not be nil", first, second)
+			}
+		}
+	}
+}
+
+func TestAuditedMessages(t *testing.T) {
+	//Arrange
+	user, _ := newSignedMessage(t)
+	log, err := services.NewAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageService := services.NewAuditedMessageService(log)
+
+	messageService.NewMessage(user, "first")
+	messageService.NewMessage(user, "second")
+	oldHead, _ := log.TreeHead()
+	messageService.NewMessage(user, "third")
+	message, _ := messageService.NewMessage(user, "fourth")
+	messageService.NewMessage(user, "fifth")
+
+	//Act
+	head, err := log.TreeHead()
+	inclusion, inclusionErr := log.InclusionProof(3, head.TreeSize)
+	consistency, consistencyErr := log.ConsistencyProof(oldHead.TreeSize, head.TreeSize)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("TreeHead error should be nil, got %s", err.Error())
+	}
+	if head.TreeSize != 5 {
+		t.Errorf("Expected tree size 5, got %d", head.TreeSize)
+	}
+	if err := services.VerifyTreeHead(log.PublicKey(), head); err != nil {
+		t.Errorf("VerifyTreeHead error should be nil, got %s", err.Error())
+	}
+	if inclusionErr != nil || consistencyErr != nil {
+		t.Fatalf("Proof errors should be nil, got %v and %v", inclusionErr, consistencyErr)
+	}
+	if err := services.VerifyMessageInclusion(head, message, 3, inclusion); err != nil {
+		t.Errorf("VerifyMessageInclusion error should be nil, got %s", err.Error())
+	}
+	if err := services.VerifyTreeConsistency(oldHead, head, consistency); err != nil {
+		t.Errorf("VerifyTreeConsistency error should be nil, got %s", err.Error())
+	}
+
+	altered := message
+	altered.Payload = []byte("altered")
+	if services.VerifyMessageInclusion(head, altered, 3, inclusion) == nil {
+		t.Errorf("VerifyMessageInclusion error for an altered message should not be nil")
+	}
+
+	forged := head
+	forged.TreeSize = 4
+	if services.VerifyTreeHead(log.PublicKey(), forged) == nil {
+		t.Errorf("VerifyTreeHead error for a modified tree head should not be nil")
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/passwords"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+// Run with go test -race to catch unsynchronized access.
+
+func TestDatabaseConcurrentAccess(t *testing.T) {
+	for name, newDatabase := range databases {
+		t.Run(name, func(t *testing.T) {
+			//Arrange
+			db := newDatabase(t)
+			var wg sync.WaitGroup
+
+			//Act
+			for g := 0; g < 16; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 20; i++ {
+						username := fmt.Sprintf("user%d", (g+i)%8)
+						db.Set(username, domain.User{Username: username, Password: []byte("password")})
+						db.Get(username)
+						if i%5 == 0 {
+							db.Delete(username)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			//Assert
+			for i := 0; i < 8; i++ {
+				username := fmt.Sprintf("user%d", i)
+				if user, err := db.Get(username); err == nil && user.Username != username {
+					t.Errorf("Expected username '%s', got '%s'", username, user.Username)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentRegisterSameUser(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	userService := services.NewUserServiceWithHasher(db, fastHasher(passwords.Argon2id))
+	var succeeded int32
+	var wg sync.WaitGroup
+
+	//Act
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if userService.Register("darkcat", "villv013") == nil {
+				atomic.AddInt32(&succeeded, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	//Assert
+	if succeeded != 1 {
+		t.Fatalf("Expected exactly one successful registration, got %d", succeeded)
+	}
+	if _, err := userService.Login("darkcat", "villv013"); err != nil {
+		t.Fatalf("Login error should be nil, got %s", err.Error())
+	}
+}
+
+func TestConcurrentRegisterAndLogin(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	userService := services.NewUserServiceWithHasher(db, fastHasher(passwords.Argon2id))
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+
+	//Act
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			username := fmt.Sprintf("darkcat%d", g)
+			if err := userService.Register(username, "villv013"); err != nil {
+				errs <- err
+				return
+			}
+			for i := 0; i < 3; i++ {
+				if _, err := userService.Login(username, "villv013"); err != nil {
+					errs <- err
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	//Assert
+	for err := range errs {
+		t.Errorf("Expected no errors, got %s", err.Error())
+	}
+}
@@ -0,0 +1,275 @@
+package tests
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+)
+
+// databases lists every IDatabase implementation the conformance tests run against.
+var databases = map[string]func(t *testing.T) interfaces.IDatabase{
+	"InMemory": func(t *testing.T) interfaces.IDatabase {
+		return database.NewDatabase()
+	},
+	"File": func(t *testing.T) interfaces.IDatabase {
+		db, err := database.NewFileDatabase(filepath.Join(t.TempDir(), "users.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	},
+}
+
+func newTestUser(t *testing.T, username string) domain.User {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain.User{Username: username, Password: []byte("hashed-" + username), Key: key}
+}
+
+func assertSameUser(t *testing.T, expected, actual domain.User) {
+	t.Helper()
+	if actual.Username != expected.Username {
+		t.Errorf("Expected username '%s', got '%s'", expected.Username, actual.Username)
+	}
+	if !bytes.Equal(actual.Password, expected.Password) {
+		t.Errorf("Expected password '%s', got '%s'", expected.Password, actual.Password)
+	}
+	if !sameKey(actual.Key, expected.Key) {
+		t.Errorf("Expected stored key to equal the original key")
+	}
+}
+
+// sameKey compares private keys of any type the standard library generates.
+func sameKey(a, b crypto.Signer) bool {
+	key, ok := a.(interface{ Equal(crypto.PrivateKey) bool })
+	return ok && key.Equal(b)
+}
+
+func TestDatabaseConformance(t *testing.T) {
+	for name, newDatabase := range databases {
+		t.Run(name+"/SetGet", func(t *testing.T) {
+			db := newDatabase(t)
+			user := newTestUser(t, "darkcat")
+
+			if err := db.Set(user.Username, user); err != nil {
+				t.Fatalf("Set error should be nil, got %s", err.Error())
+			}
+			got, err := db.Get(user.Username)
+
+			if err != nil {
+				t.Fatalf("Get error should be nil, got %s", err.Error())
+			}
+			assertSameUser(t, user, got)
+		})
+
+		t.Run(name+"/GetMissing", func(t *testing.T) {
+			db := newDatabase(t)
+
+			_, err := db.Get("nobody")
+
+			if err == nil {
+				t.Errorf("Get error should not be nil")
+			}
+		})
+
+		t.Run(name+"/Overwrite", func(t *testing.T) {
+			db := newDatabase(t)
+			db.Set("darkcat", newTestUser(t, "darkcat"))
+			updated := newTestUser(t, "darkcat")
+
+			db.Set("darkcat", updated)
+			got, err := db.Get("darkcat")
+
+			if err != nil {
+				t.Fatalf("Get error should be nil, got %s", err.Error())
+			}
+			assertSameUser(t, updated, got)
+		})
+
+		t.Run(name+"/Delete", func(t *testing.T) {
+			db := newDatabase(t)
+			db.Set("darkcat", newTestUser(t, "darkcat"))
+
+			err := db.Delete("darkcat")
+			_, getErr := db.Get("darkcat")
+			missingErr := db.Delete("darkcat")
+
+			if err != nil {
+				t.Errorf("Delete error should be nil, got %s", err.Error())
+			}
+			if getErr == nil {
+				t.Errorf("Get error after delete should not be nil")
+			}
+			if missingErr == nil {
+				t.Errorf("Delete error for missing user should not be nil")
+			}
+		})
+
+		t.Run(name+"/List", func(t *testing.T) {
+			db := newDatabase(t)
+			for i := 4; i >= 0; i-- {
+				username := fmt.Sprintf("darkcat%d", i)
+				db.Set(username, domain.User{Username: username})
+			}
+			db.Set("other", domain.User{Username: "other"})
+
+			all, err := db.List(domain.UserFilter{}, domain.Pagination{})
+			page, _ := db.List(domain.UserFilter{UsernamePrefix: "darkcat"}, domain.Pagination{Offset: 1, Limit: 2})
+			past, _ := db.List(domain.UserFilter{}, domain.Pagination{Offset: 10})
+
+			if err != nil {
+				t.Fatalf("List error should be nil, got %s", err.Error())
+			}
+			if len(all) != 6 {
+				t.Errorf("Expected 6 users, got %d", len(all))
+			}
+			if len(page) != 2 || page[0].Username != "darkcat1" || page[1].Username != "darkcat2" {
+				t.Errorf("Expected page [darkcat1 darkcat2], got %v", page)
+			}
+			if len(past) != 0 {
+				t.Errorf("Expected empty page past the end, got %d users", len(past))
+			}
+		})
+
+		t.Run(name+"/CompareAndSet", func(t *testing.T) {
+			db := newDatabase(t)
+
+			insertErr := db.CompareAndSet("darkcat", domain.User{Username: "darkcat", Password: []byte("v1")})
+			duplicateErr := db.CompareAndSet("darkcat", domain.User{Username: "darkcat"})
+			first, _ := db.Get("darkcat")
+			second := first
+
+			first.Password = []byte("v2")
+			firstErr := db.CompareAndSet("darkcat", first)
+			second.Password = []byte("lost update")
+			secondErr := db.CompareAndSet("darkcat", second)
+			stored, _ := db.Get("darkcat")
+
+			if insertErr != nil || firstErr != nil {
+				t.Fatalf("CompareAndSet errors should be nil, got %v, %v", insertErr, firstErr)
+			}
+			if !errors.Is(duplicateErr, interfaces.ErrVersionConflict) || !errors.Is(secondErr, interfaces.ErrVersionConflict) {
+				t.Errorf("Expected ErrVersionConflict, got %v, %v", duplicateErr, secondErr)
+			}
+			if string(stored.Password) != "v2" || stored.Version != 2 {
+				t.Errorf("Expected password 'v2' at version 2, got '%s' at version %d", stored.Password, stored.Version)
+			}
+		})
+
+		t.Run(name+"/WithTxCommit", func(t *testing.T) {
+			db := newDatabase(t)
+			db.Set("darkcat", domain.User{Username: "darkcat"})
+
+			err := db.WithTx(func(tx interfaces.ITransaction) error {
+				if err := tx.Delete("darkcat"); err != nil {
+					return err
+				}
+				if _, err := tx.Get("darkcat"); err == nil {
+					return errors.New("deleted user visible inside transaction")
+				}
+				return tx.Set("darkcat1", domain.User{Username: "darkcat1"})
+			})
+			_, deletedErr := db.Get("darkcat")
+			_, insertedErr := db.Get("darkcat1")
+
+			if err != nil {
+				t.Fatalf("WithTx error should be nil, got %s", err.Error())
+			}
+			if deletedErr == nil || insertedErr != nil {
+				t.Errorf("Expected both transaction writes to be committed")
+			}
+		})
+
+		t.Run(name+"/WithTxRollback", func(t *testing.T) {
+			db := newDatabase(t)
+			db.Set("darkcat", domain.User{Username: "darkcat"})
+			failure := errors.New("abort")
+
+			err := db.WithTx(func(tx interfaces.ITransaction) error {
+				tx.Delete("darkcat")
+				tx.Set("darkcat1", domain.User{Username: "darkcat1"})
+				return failure
+			})
+			_, keptErr := db.Get("darkcat")
+			_, insertedErr := db.Get("darkcat1")
+
+			if !errors.Is(err, failure) {
+				t.Fatalf("Expected WithTx to return the callback error, got %v", err)
+			}
+			if keptErr != nil || insertedErr == nil {
+				t.Errorf("Expected no transaction writes to be committed")
+			}
+		})
+	}
+}
+
+func TestCompareAndSetUnversionedUser(t *testing.T) {
+	//Arrange
+	db := &database.InMemoryDatabase{Users: map[string]domain.User{
+		"darkcat": {Username: "darkcat", Password: []byte("stored")},
+	}}
+
+	//Act
+	insertErr := db.CompareAndSet("darkcat", domain.User{Username: "darkcat", Password: []byte("inserted")})
+	txErr := db.WithTx(func(tx interfaces.ITransaction) error {
+		return tx.CompareAndSet("darkcat", domain.User{Username: "darkcat", Password: []byte("inserted")})
+	})
+	stored, _ := db.Get("darkcat")
+	stored.Password = []byte("updated")
+	updateErr := db.CompareAndSet("darkcat", stored)
+	got, _ := db.Get("darkcat")
+
+	//Assert
+	if !errors.Is(insertErr, interfaces.ErrVersionConflict) || !errors.Is(txErr, interfaces.ErrVersionConflict) {
+		t.Errorf("Expected version 0 inserts over an unversioned user to conflict, got %v and %v", insertErr, txErr)
+	}
+	if updateErr != nil {
+		t.Fatalf("CompareAndSet error with the read version should be nil, got %s", updateErr.Error())
+	}
+	if string(got.Password) != "updated" || got.Version != 2 {
+		t.Errorf("Expected the updated user at version 2, got '%s' at %d", got.Password, got.Version)
+	}
+}
+
+func TestFileDatabasePersists(t *testing.T) {
+	//Arrange
+	path := filepath.Join(t.TempDir(), "users.json")
+	db, err := database.NewFileDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := newTestUser(t, "darkcat")
+	removed := newTestUser(t, "darkcat1")
+	db.Set(kept.Username, kept)
+	db.Set(removed.Username, removed)
+	db.Delete(removed.Username)
+
+	//Act
+	reopened, err := database.NewFileDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Get(kept.Username)
+	_, removedErr := reopened.Get(removed.Username)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Get error should be nil, got %s", err.Error())
+	}
+	assertSameUser(t, kept, got)
+	if removedErr == nil {
+		t.Errorf("Get error for deleted user should not be nil")
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+func newSignedMessage(t *testing.T) (domain.User, domain.SignedMessage) {
+	t.Helper()
+	db := database.NewDatabase()
+	userService := services.NewUserService(db)
+	userService.Register("darkcat", "villv013")
+	user, err := userService.Login("darkcat", "villv013")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := services.NewMessageService().NewMessage(user, "Very important message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, message
+}
+
+func TestEnvelopeContents(t *testing.T) {
+	//Act
+	user, message := newSignedMessage(t)
+
+	//Assert
+	if string(message.Payload) != "Very important message" {
+		t.Errorf("Expected payload 'Very important message', got '%s'", message.Payload)
+	}
+	if message.Signer != "darkcat" {
+		t.Errorf("Expected signer 'darkcat', got '%s'", message.Signer)
+	}
+	if message.KeyID != utils.GetKeyID(user.Key.Public()) {
+		t.Errorf("Expected key id '%s', got '%s'", utils.GetKeyID(user.Key.Public()), message.KeyID)
+	}
+	if message.HashAlgorithm != domain.HashSHA256 || message.SignatureScheme != domain.SchemePKCS1v15 {
+		t.Errorf("Unexpected algorithms '%s', '%s'", message.HashAlgorithm, message.SignatureScheme)
+	}
+	if message.Timestamp.IsZero() {
+		t.Errorf("Expected timestamp to be set")
+	}
+}
+
+func TestEnvelopeTamperedFieldsFail(t *testing.T) {
+	//Arrange
+	user, message := newSignedMessage(t)
+	messageService := services.NewMessageService()
+
+	tamperedPayload := message
+	tamperedPayload.Payload = []byte("Very important message!")
+	tamperedSigner := message
+	tamperedSigner.Signer = "darkcat1"
+	tamperedTime := message
+	tamperedTime.Timestamp = message.Timestamp.Add(-1)
+
+	for name, tampered := range map[string]domain.SignedMessage{
+		"payload":   tamperedPayload,
+		"signer":    tamperedSigner,
+		"timestamp": tamperedTime,
+	} {
+		//Act
+		err := messageService.CheckMessage(user.Key.Public(), tampered)
+
+		//Assert
+		if err == nil {
+			t.Errorf("CheckMessage error should not be nil for tampered %s", name)
+		}
+	}
+}
+
+func TestEnvelopeJSONRoundTrip(t *testing.T) {
+	//Arrange
+	user, message := newSignedMessage(t)
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	var decoded domain.SignedMessage
+	err = json.Unmarshal(data, &decoded)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Unmarshal error should be nil, got %s", err.Error())
+	}
+	if err := services.NewMessageService().CheckMessage(user.Key.Public(), decoded); err != nil {
+		t.Errorf("CheckMessage error should be nil, got %s", err.Error())
+	}
+}
+
+func TestEnvelopeBinaryRoundTrip(t *testing.T) {
+	//Arrange
+	user, message := newSignedMessage(t)
+	data, err := message.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	var decoded domain.SignedMessage
+	err = decoded.UnmarshalBinary(data)
+	truncatedErr := new(domain.SignedMessage).UnmarshalBinary(data[:len(data)-1])
+
+	//Assert
+	if err != nil {
+		t.Fatalf("UnmarshalBinary error should be nil, got %s", err.Error())
+	}
+	if !bytes.Equal(decoded.Payload, message.Payload) || !decoded.Timestamp.Equal(message.Timestamp) {
+		t.Errorf("Decoded envelope differs from the original")
+	}
+	if err := services.NewMessageService().CheckMessage(user.Key.Public(), decoded); err != nil {
+		t.Errorf("CheckMessage error should be nil, got %s", err.Error())
+	}
+	if truncatedErr == nil {
+		t.Errorf("UnmarshalBinary error should not be nil for truncated data")
+	}
+}
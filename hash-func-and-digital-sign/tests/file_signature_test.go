@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+// patternReader yields n bytes without holding them in memory.
+type patternReader struct {
+	remaining int64
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	for i := range p {
+		p[i] = byte(i)
+	}
+	r.remaining -= int64(len(p))
+	return len(p), nil
+}
+
+func TestFileSignatureLargeStream(t *testing.T) {
+	//Arrange
+	user, _ := newSignedMessage(t)
+	fileService := services.NewFileSignatureService()
+	const size = 64 << 20
+
+	signature, err := fileService.Sign(user, &patternReader{remaining: size})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	err = fileService.Check(user.Key.Public(), &patternReader{remaining: size}, signature)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Check error should be nil, got %s", err.Error())
+	}
+	if signature.Size != size {
+		t.Errorf("Expected size %d, got %d", size, signature.Size)
+	}
+}
+
+func TestFileSignatureModifiedContent(t *testing.T) {
+	//Arrange
+	user, _ := newSignedMessage(t)
+	fileService := services.NewFileSignatureService()
+	content := []byte("file.tar contents")
+
+	signature, err := fileService.Sign(user, bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	err = fileService.Check(user.Key.Public(), strings.NewReader("file.tar contents!"), signature)
+
+	//Assert
+	if err == nil {
+		t.Fatalf("Check error should not be nil")
+	}
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+func TestHashRegistryKnownDigests(t *testing.T) {
+	expected := map[string]string{
+		domain.HashSHA224:     "23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7",
+		domain.HashSHA256:     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		domain.HashSHA3_256:   "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		domain.HashBLAKE2s256: "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982",
+		domain.HashBLAKE2b512: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+	}
+
+	for name, expectedDigest := range expected {
+		//Act
+		digest, err := utils.GetDigest(name, []byte("abc"))
+
+		//Assert
+		if err != nil {
+			t.Errorf("%s: GetDigest error should be nil, got %s", name, err.Error())
+			continue
+		}
+		if hex.EncodeToString(digest) != expectedDigest {
+			t.Errorf("%s: Expected digest '%s', got '%x'", name, expectedDigest, digest)
+		}
+	}
+}
+
+func TestHashRegistryStreamMatchesOneShot(t *testing.T) {
+	input := strings.Repeat("streamed input ", 10000)
+
+	for _, name := range utils.HashNames() {
+		//Act
+		digest, _ := utils.GetDigest(name, []byte(input))
+		streamed, size, err := utils.GetStreamDigest(name, strings.NewReader(input))
+
+		//Assert
+		if err != nil {
+			t.Errorf("%s: GetStreamDigest error should be nil, got %s", name, err.Error())
+			continue
+		}
+		if !bytes.Equal(digest, streamed) || size != int64(len(input)) {
+			t.Errorf("%s: streamed digest differs from one-shot digest", name)
+		}
+	}
+}
+
+func TestSignAndCheckWithEveryHash(t *testing.T) {
+	//Arrange
+	user, _ := newSignedMessage(t)
+
+	for _, name := range utils.HashNames() {
+		messageService, err := services.NewMessageServiceWithHash(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		//Act
+		message, err := messageService.NewMessage(user, "Very important message")
+		if err != nil {
+			t.Errorf("%s: NewMessage error should be nil, got %s", name, err.Error())
+			continue
+		}
+		checkErr := messageService.CheckMessage(user.Key.Public(), message)
+
+		//Assert
+		if message.HashAlgorithm != name {
+			t.Errorf("%s: Expected recorded hash algorithm '%s', got '%s'", name, name, message.HashAlgorithm)
+		}
+		if checkErr != nil {
+			t.Errorf("%s: CheckMessage error should be nil, got %s", name, checkErr.Error())
+		}
+	}
+}
+
+func TestOldSignaturesCheckAfterMigration(t *testing.T) {
+	//Arrange
+	user, oldMessage := newSignedMessage(t)
+	migrated, err := services.NewMessageServiceWithHash(domain.HashSHA3_512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	err = migrated.CheckMessage(user.Key.Public(), oldMessage)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("CheckMessage error should be nil, got %s", err.Error())
+	}
+}
+
+func TestUnknownHashAlgorithm(t *testing.T) {
+	//Act
+	_, err := services.NewMessageServiceWithHash("MD5")
+
+	//Assert
+	if err == nil {
+		t.Fatalf("NewMessageServiceWithHash error should not be nil")
+	}
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+func TestHmacMessage(t *testing.T) {
+	for _, hashAlgorithm := range []string{domain.HashSHA256, domain.HashSHA512} {
+		//Arrange
+		secrets := database.NewSecretStore()
+		keyID, _, err := secrets.Generate("billing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		messageService, _ := services.NewHmacMessageServiceWithHash(secrets, hashAlgorithm)
+
+		//Act
+		message, err := messageService.NewMessage(domain.User{Username: "billing"}, "invoice paid")
+		checkErr := messageService.CheckMessage(nil, message)
+
+		//Assert
+		if err != nil {
+			t.Fatalf("NewMessage error should be nil, got %s", err.Error())
+		}
+		if message.KeyID != keyID || message.SignatureScheme != domain.SchemeHMAC {
+			t.Errorf("Expected key id %s and scheme %s, got %s and %s", keyID, domain.SchemeHMAC, message.KeyID, message.SignatureScheme)
+		}
+		if checkErr != nil {
+			t.Errorf("%s: CheckMessage error should be nil, got %s", hashAlgorithm, checkErr.Error())
+		}
+	}
+}
+
+func TestHmacMessageRejected(t *testing.T) {
+	//Arrange
+	secrets := database.NewSecretStore()
+	secrets.Generate("billing")
+	messageService := services.NewHmacMessageService(secrets)
+	message, _ := messageService.NewMessage(domain.User{Username: "billing"}, "invoice paid")
+
+	altered := message
+	altered.Payload = []byte("invoice refunded")
+	impersonated := message
+	impersonated.Signer = "shipping"
+
+	otherSecrets := database.NewSecretStore()
+	otherSecrets.Add("billing", message.KeyID, []byte("another secret"))
+
+	//Act
+	alteredErr := messageService.CheckMessage(nil, altered)
+	impersonatedErr := messageService.CheckMessage(nil, impersonated)
+	otherErr := services.NewHmacMessageService(otherSecrets).CheckMessage(nil, message)
+
+	//Assert
+	if alteredErr == nil {
+		t.Errorf("CheckMessage error for an altered message should not be nil")
+	}
+	if impersonatedErr == nil {
+		t.Errorf("CheckMessage error for another sender should not be nil")
+	}
+	if otherErr == nil {
+		t.Errorf("CheckMessage error with another secret should not be nil")
+	}
+}
+
+func TestHmacSecretRotation(t *testing.T) {
+	//Arrange
+	secrets := database.NewSecretStore()
+	secrets.Generate("billing")
+	messageService := services.NewHmacMessageService(secrets)
+	old, _ := messageService.NewMessage(domain.User{Username: "billing"}, "invoice paid")
+
+	//Act
+	keyID, _, _ := secrets.Generate("billing")
+	rotated, _ := messageService.NewMessage(domain.User{Username: "billing"}, "invoice paid")
+
+	//Assert
+	if rotated.KeyID != keyID || old.KeyID == keyID {
+		t.Errorf("Expected new messages to use the newest secret")
+	}
+	if err := messageService.CheckMessage(nil, old); err != nil {
+		t.Errorf("CheckMessage error for a message with an older secret should be nil, got %s", err.Error())
+	}
+}
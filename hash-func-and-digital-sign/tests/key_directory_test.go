@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+func newKeyDirectory(t *testing.T) (interfaces.IKeyDirectory, interfaces.ICertificateAuthority) {
+	t.Helper()
+	db := database.NewDatabase()
+	services.NewUserService(db).Register("darkcat", "villv013")
+
+	authority, err := services.NewCertificateAuthority("cs-labs root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return services.NewKeyDirectoryService(db, authority), authority
+}
+
+func TestKeyDirectoryLookup(t *testing.T) {
+	//Arrange
+	directory, _ := newKeyDirectory(t)
+	_, message := newSignedMessage(t)
+
+	//Act
+	_, err := directory.Lookup("darkcat", message.KeyID)
+	_, unknownErr := directory.Lookup("nobody", message.KeyID)
+
+	//Assert
+	if err == nil {
+		t.Errorf("Lookup of a key from another database should fail")
+	}
+	if unknownErr == nil {
+		t.Errorf("Lookup of an unknown user should fail")
+	}
+}
+
+func TestCertifiedMessageCheck(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	userService := services.NewUserService(db)
+	userService.Register("darkcat", "villv013")
+	user, _ := userService.Login("darkcat", "villv013")
+
+	authority, err := services.NewCertificateAuthority("cs-labs root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	directory := services.NewKeyDirectoryService(db, authority)
+	messageService := services.NewMessageService()
+	message, _ := messageService.NewMessage(user, "Very important message")
+
+	//Act
+	publicKey, lookupErr := directory.Lookup(message.Signer, message.KeyID)
+	certificate, certErr := directory.Certificate(message.Signer, message.KeyID)
+	checkErr := services.CheckCertifiedMessage(messageService, authority, directory, certificate, message)
+
+	//Assert
+	if lookupErr != nil {
+		t.Fatalf("Lookup error should be nil, got %s", lookupErr.Error())
+	}
+	if utils.GetKeyID(publicKey) != message.KeyID {
+		t.Errorf("Lookup returned another key")
+	}
+	if certErr != nil {
+		t.Fatalf("Certificate error should be nil, got %s", certErr.Error())
+	}
+	if certificate.Subject.CommonName != "darkcat" {
+		t.Errorf("Expected certificate subject 'darkcat', got '%s'", certificate.Subject.CommonName)
+	}
+	if checkErr != nil {
+		t.Errorf("CheckCertifiedMessage error should be nil, got %s", checkErr.Error())
+	}
+}
+
+func TestCertificateFromAnotherAuthority(t *testing.T) {
+	//Arrange
+	user, message := newSignedMessage(t)
+	directory, authority := newKeyDirectory(t)
+	rogue, _ := services.NewCertificateAuthority("cs-labs root")
+	certificate, err := rogue.Issue(user.Username, user.Key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	err = services.CheckCertifiedMessage(services.NewMessageService(), authority, directory, certificate, message)
+
+	//Assert
+	if err == nil {
+		t.Fatalf("CheckCertifiedMessage error should not be nil")
+	}
+}
+
+func TestCertificateForAnotherUser(t *testing.T) {
+	//Arrange
+	user, message := newSignedMessage(t)
+	directory, authority := newKeyDirectory(t)
+	certificate, _ := authority.Issue("mallory", user.Key.Public())
+
+	//Act
+	err := services.CheckCertifiedMessage(services.NewMessageService(), authority, directory, certificate, message)
+
+	//Assert
+	if err == nil {
+		t.Fatalf("CheckCertifiedMessage error should not be nil")
+	}
+}
+
+func TestCertifiedMessageAfterRevocation(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	userService := services.NewUserService(db)
+	userService.Register("darkcat", "villv013")
+	user, _ := userService.Login("darkcat", "villv013")
+
+	authority, err := services.NewCertificateAuthority("cs-labs root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	directory := services.NewKeyDirectoryService(db, authority)
+	messageService := services.NewMessageService()
+	before, _ := messageService.NewMessage(user, "Signed before the revocation")
+	certificate, err := directory.Certificate(before.Signer, before.KeyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	revokeErr := services.NewKeyServiceWithDirectory(db, directory).Revoke("darkcat", before.KeyID, domain.ReasonKeyCompromise)
+	after, _ := messageService.NewMessage(user, "Signed with the compromised key")
+	beforeErr := services.CheckCertifiedMessage(messageService, authority, directory, certificate, before)
+	afterErr := services.CheckCertifiedMessage(messageService, authority, directory, certificate, after)
+	_, certErr := directory.Certificate(before.Signer, before.KeyID)
+
+	//Assert
+	if revokeErr != nil {
+		t.Fatalf("Revoke error should be nil, got %s", revokeErr.Error())
+	}
+	if beforeErr != nil {
+		t.Errorf("CheckCertifiedMessage error for a message signed before the revocation should be nil, got %s", beforeErr.Error())
+	}
+	if afterErr == nil {
+		t.Errorf("CheckCertifiedMessage error for a message signed after the revocation should not be nil")
+	}
+	if certErr == nil {
+		t.Errorf("Certificate error for a revoked key should not be nil")
+	}
+}
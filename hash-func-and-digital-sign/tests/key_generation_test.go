@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+func TestKeyGeneratorAlgorithms(t *testing.T) {
+	expectedBits := map[string]int{
+		domain.KeyRSA2048:   2048,
+		domain.KeyRSA3072:   3072,
+		domain.KeyECDSAP256: 256,
+		domain.KeyECDSAP384: 384,
+	}
+
+	for algorithm, bits := range expectedBits {
+		keys, err := services.NewKeyGenerator(algorithm)
+		if err != nil {
+			t.Fatalf("NewKeyGenerator error should be nil, got %s", err.Error())
+		}
+
+		//Act
+		key, err := keys.Generate()
+
+		//Assert
+		if err != nil {
+			t.Fatalf("%s: Generate error should be nil, got %s", algorithm, err.Error())
+		}
+		var actual int
+		switch publicKey := key.Public().(type) {
+		case *rsa.PublicKey:
+			actual = publicKey.N.BitLen()
+		case *ecdsa.PublicKey:
+			actual = publicKey.Curve.Params().BitSize
+		}
+		if actual != bits {
+			t.Errorf("%s: Expected a %d bit key, got %d", algorithm, bits, actual)
+		}
+	}
+
+	if _, err := services.NewKeyGenerator("RSA-1028"); err == nil {
+		t.Errorf("NewKeyGenerator error for an unsupported algorithm should not be nil")
+	}
+}
+
+func TestDefaultKeyIsNotWeak(t *testing.T) {
+	//Act
+	user, _ := newSignedMessage(t)
+
+	//Assert
+	publicKey, ok := user.Key.Public().(*rsa.PublicKey)
+	if !ok || publicKey.N.BitLen() < 2048 {
+		t.Errorf("Expected a default RSA key of at least 2048 bits")
+	}
+}
+
+func TestEcdsaUserSignatures(t *testing.T) {
+	for name, newDatabase := range databases {
+		t.Run(name, func(t *testing.T) {
+			//Arrange
+			db := newDatabase(t)
+			keys, _ := services.NewKeyGenerator(domain.KeyECDSAP256)
+			userService := services.NewUserServiceWithKeys(db, keys)
+			userService.Register("darkcat", "villv013")
+			user, err := userService.Login("darkcat", "villv013")
+			if err != nil {
+				t.Fatal(err)
+			}
+			messageService := services.NewMessageService()
+			fileService := services.NewFileSignatureService()
+
+			//Act
+			message, messageErr := messageService.NewMessage(user, "Very important message")
+			signature, fileErr := fileService.Sign(user, bytes.NewReader([]byte("file.tar contents")))
+
+			//Assert
+			if messageErr != nil || fileErr != nil {
+				t.Fatalf("Signing errors should be nil, got %v and %v", messageErr, fileErr)
+			}
+			if message.SignatureScheme != domain.SchemeECDSA {
+				t.Errorf("Expected scheme '%s', got '%s'", domain.SchemeECDSA, message.SignatureScheme)
+			}
+			if err := messageService.CheckMessage(user.Key.Public(), message); err != nil {
+				t.Errorf("CheckMessage error should be nil, got %s", err.Error())
+			}
+			if err := fileService.Check(user.Key.Public(), bytes.NewReader([]byte("file.tar contents")), signature); err != nil {
+				t.Errorf("Check error should be nil, got %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestKeyPool(t *testing.T) {
+	//Arrange
+	keys, _ := services.NewKeyGenerator(domain.KeyECDSAP256)
+	pool, err := services.NewKeyPool(keys, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Available() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	db := database.NewDatabase()
+	userService := services.NewUserServiceWithKeys(db, pool)
+
+	//Act
+	seen := make(map[string]bool)
+	for i := 0; i < 8; i++ {
+		username := fmt.Sprintf("user%d", i)
+		if err := userService.Register(username, "villv013"); err != nil {
+			t.Fatalf("Register error should be nil, got %s", err.Error())
+		}
+		user, _ := db.Get(username)
+		seen[user.Keys[0].ID] = true
+	}
+
+	//Assert
+	if len(seen) != 8 {
+		t.Errorf("Expected 8 distinct keys, got %d", len(seen))
+	}
+}
+
+func TestKeyPoolClose(t *testing.T) {
+	//Arrange
+	keys, _ := services.NewKeyGenerator(domain.KeyECDSAP256)
+	pool, _ := services.NewKeyPool(keys, 2, 4)
+
+	//Act
+	pool.Close()
+	pool.Close()
+	key, err := pool.Generate()
+
+	//Assert
+	if err != nil || key == nil {
+		t.Errorf("Generate after Close should fall back to generating a key")
+	}
+	if _, err := services.NewKeyPool(keys, 0, 1); err == nil {
+		t.Errorf("NewKeyPool error for an empty pool should not be nil")
+	}
+}
+
+// flakyGenerator fails its first calls, like a temporarily exhausted entropy
+// source would.
+type flakyGenerator struct {
+	mu    sync.Mutex
+	fails int
+	keys  interfaces.IKeyGenerator
+}
+
+func (g *flakyGenerator) Generate() (crypto.Signer, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.fails > 0 {
+		g.fails--
+		return nil, errors.New("flakyGenerator | temporary failure")
+	}
+	return g.keys.Generate()
+}
+
+func TestKeyPoolRetriesAfterFailure(t *testing.T) {
+	//Arrange
+	keys, _ := services.NewKeyGenerator(domain.KeyECDSAP256)
+	pool, _ := services.NewKeyPool(&flakyGenerator{fails: 2, keys: keys}, 4, 1)
+	defer pool.Close()
+
+	//Act
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Available() < 4 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	//Assert
+	if pool.Available() != 4 {
+		t.Errorf("Expected the pool to refill after failures, got %d keys", pool.Available())
+	}
+}
+
+func TestDefaultServicesUseKeyPool(t *testing.T) {
+	//Act
+	userService := services.NewUserService(database.NewDatabase()).(*services.UserService)
+	keyService := services.NewKeyService(database.NewDatabase()).(*services.KeyService)
+
+	//Assert
+	if _, ok := userService.Keys.(*services.KeyPool); !ok {
+		t.Errorf("Expected the default user service to take keys from a KeyPool")
+	}
+	if keyService.Keys != userService.Keys {
+		t.Errorf("Expected the default services to share one KeyPool")
+	}
+}
+
+func TestLazyKeyPoolStartsOnFirstGenerate(t *testing.T) {
+	//Arrange
+	keys, _ := services.NewKeyGenerator(domain.KeyECDSAP256)
+	pool, _ := services.NewLazyKeyPool(keys, 4, 1)
+	defer pool.Close()
+	time.Sleep(50 * time.Millisecond)
+	idle := pool.Available()
+
+	//Act
+	_, err := pool.Generate()
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Available() < 4 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Generate error should be nil, got %s", err.Error())
+	}
+	if idle != 0 {
+		t.Errorf("Expected a lazy pool to stay empty before Generate, got %d keys", idle)
+	}
+	if pool.Available() != 4 {
+		t.Errorf("Expected the pool to fill after Generate, got %d keys", pool.Available())
+	}
+}
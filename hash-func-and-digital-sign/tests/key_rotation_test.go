@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+func TestKeyRotation(t *testing.T) {
+	for name, newDatabase := range databases {
+		t.Run(name, func(t *testing.T) {
+			//Arrange
+			db := newDatabase(t)
+			userService := services.NewUserService(db)
+			userService.Register("darkcat", "villv013")
+			before, _ := userService.Login("darkcat", "villv013")
+			messageService := services.NewMessageService()
+			oldMessage, _ := messageService.NewMessage(before, "Very important message")
+
+			//Act
+			key, err := services.NewKeyService(db).Rotate("darkcat")
+			after, _ := userService.Login("darkcat", "villv013")
+			newMessage, _ := messageService.NewMessage(after, "Very important message")
+			directory := services.NewKeyDirectoryService(db, nil)
+
+			//Assert
+			if err != nil {
+				t.Fatalf("Rotate error should be nil, got %s", err.Error())
+			}
+			if len(after.Keys) != 2 || newMessage.KeyID != key.ID || oldMessage.KeyID == key.ID {
+				t.Fatalf("Expected new messages to be signed with the rotated key")
+			}
+			if old, _ := after.FindKey(oldMessage.KeyID); old.Status != domain.KeyRetired {
+				t.Errorf("Expected old key status '%s', got '%s'", domain.KeyRetired, old.Status)
+			}
+			if err := services.CheckPublishedMessage(messageService, directory, oldMessage); err != nil {
+				t.Errorf("Old message check error should be nil, got %s", err.Error())
+			}
+			if err := services.CheckPublishedMessage(messageService, directory, newMessage); err != nil {
+				t.Errorf("New message check error should be nil, got %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestKeyRevocation(t *testing.T) {
+	for name, newDatabase := range databases {
+		t.Run(name, func(t *testing.T) {
+			//Arrange
+			db := newDatabase(t)
+			userService := services.NewUserService(db)
+			userService.Register("darkcat", "villv013")
+			user, _ := userService.Login("darkcat", "villv013")
+			messageService := services.NewMessageService()
+			keyService := services.NewKeyService(db)
+			directory := services.NewKeyDirectoryService(db, nil)
+
+			beforeRevocation, _ := messageService.NewMessage(user, "Very important message")
+			keyID := beforeRevocation.KeyID
+			time.Sleep(time.Millisecond)
+
+			//Act
+			err := keyService.Revoke("darkcat", keyID, domain.ReasonKeyCompromise)
+			afterRevocation, _ := messageService.NewMessage(user, "Forged message")
+			revoked, _ := userService.Login("darkcat", "villv013")
+			_, signErr := messageService.NewMessage(revoked, "Very important message")
+			list, listErr := keyService.RevocationList()
+
+			//Assert
+			if err != nil {
+				t.Fatalf("Revoke error should be nil, got %s", err.Error())
+			}
+			if err := services.CheckPublishedMessage(messageService, directory, beforeRevocation); err != nil {
+				t.Errorf("Check error for message signed before revocation should be nil, got %s", err.Error())
+			}
+			if err := services.CheckPublishedMessage(messageService, directory, afterRevocation); err == nil {
+				t.Errorf("Check error for message signed after revocation should not be nil")
+			}
+			if signErr == nil {
+				t.Errorf("NewMessage error without an active key should not be nil")
+			}
+			if listErr != nil {
+				t.Fatalf("RevocationList error should be nil, got %s", listErr.Error())
+			}
+			if len(list) != 1 || list[0].KeyID != keyID || list[0].Reason != domain.ReasonKeyCompromise {
+				t.Errorf("Expected revocation list with key %s, got %v", keyID, list)
+			}
+			if err := keyService.Revoke("darkcat", keyID, domain.ReasonSuperseded); err == nil {
+				t.Errorf("Revoke error for a revoked key should not be nil")
+			}
+		})
+	}
+}
+
+func TestRotateLegacyUser(t *testing.T) {
+	//Arrange
+	db := databases["File"](t)
+	legacy := newTestUser(t, "darkcat")
+	db.Set(legacy.Username, legacy)
+	message, _ := services.NewMessageService().NewMessage(legacy, "Very important message")
+
+	//Act
+	_, err := services.NewKeyService(db).Rotate("darkcat")
+	directory := services.NewKeyDirectoryService(db, nil)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Rotate error should be nil, got %s", err.Error())
+	}
+	if err := services.CheckPublishedMessage(services.NewMessageService(), directory, message); err != nil {
+		t.Errorf("Check error for message signed with the legacy key should be nil, got %s", err.Error())
+	}
+}
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+func TestSplitAndCombineSigningKey(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	userService := services.NewUserService(db)
+
+	userService.Register("darkcat", "villv013")
+	user, _ := userService.Login("darkcat", "villv013")
+
+	shares, err := utils.SplitSigningKey(user.Key, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	key, err := utils.CombineSigningKey(shares[1:])
+
+	//Assert
+	if err != nil {
+		t.Fatalf("CombineSigningKey error should be nil, got %s", err.Error())
+	}
+	if !sameKey(key, user.Key) {
+		t.Errorf("Combined key should equal the user key")
+	}
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/keystore"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+func newMasterKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, keystore.MasterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newKeyring(t *testing.T, id string) *keystore.Keyring {
+	t.Helper()
+	keyring, err := keystore.NewKeyring(id, newMasterKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestSealedKeySigns(t *testing.T) {
+	//Arrange
+	user, _ := newSignedMessage(t)
+	keyring := newKeyring(t, "m1")
+	sealed, err := keystore.Seal(keyring, user.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Key = sealed
+
+	//Act
+	message, err := services.NewMessageService().NewMessage(user, "Very important message")
+
+	//Assert
+	if err != nil {
+		t.Fatalf("NewMessage error should be nil, got %s", err.Error())
+	}
+	if err := services.NewMessageService().CheckMessage(sealed.Public(), message); err != nil {
+		t.Errorf("CheckMessage error should be nil, got %s", err.Error())
+	}
+}
+
+func TestSealedKeyRejected(t *testing.T) {
+	//Arrange
+	user, _ := newSignedMessage(t)
+	keyring := newKeyring(t, "m1")
+	sealed, _ := keystore.Seal(keyring, user.Key)
+
+	tampered := keystore.Restore(keyring, sealed.PublicKey, sealed.MasterKeyID, sealed.WrappedDataKey, append([]byte(nil), sealed.Ciphertext...))
+	tampered.Ciphertext[len(tampered.Ciphertext)-1] ^= 1
+	otherKeyring, _ := keystore.NewKeyring("m1", newMasterKey(t))
+	wrongMaster := keystore.Restore(otherKeyring, sealed.PublicKey, sealed.MasterKeyID, sealed.WrappedDataKey, sealed.Ciphertext)
+	noMaster := keystore.Restore(nil, sealed.PublicKey, sealed.MasterKeyID, sealed.WrappedDataKey, sealed.Ciphertext)
+
+	digest := make([]byte, 32)
+
+	//Act
+	_, tamperedErr := tampered.Sign(rand.Reader, digest, crypto.SHA256)
+	_, wrongMasterErr := wrongMaster.Sign(rand.Reader, digest, crypto.SHA256)
+	_, noMasterErr := noMaster.Sign(rand.Reader, digest, crypto.SHA256)
+
+	//Assert
+	if tamperedErr == nil {
+		t.Errorf("Sign error for a tampered ciphertext should not be nil")
+	}
+	if wrongMasterErr == nil {
+		t.Errorf("Sign error with another master key should not be nil")
+	}
+	if noMasterErr == nil {
+		t.Errorf("Sign error without a keyring should not be nil")
+	}
+}
+
+func TestEncryptedFileDatabase(t *testing.T) {
+	//Arrange
+	path := filepath.Join(t.TempDir(), "users.json")
+	keyring := newKeyring(t, "m1")
+	db, err := database.NewEncryptedFileDatabase(path, keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	services.NewUserService(db).Register("darkcat", "villv013")
+
+	//Act
+	data, _ := os.ReadFile(path)
+	reopened, _ := database.NewEncryptedFileDatabase(path, keyring)
+	user, loginErr := services.NewUserService(reopened).Login("darkcat", "villv013")
+	message, signErr := services.NewMessageService().NewMessage(user, "Very important message")
+
+	withoutKeyring, _ := database.NewFileDatabase(path)
+	verifier, _ := withoutKeyring.Get("darkcat")
+	_, noKeyringErr := services.NewMessageService().NewMessage(verifier, "Very important message")
+
+	//Assert
+	if bytes.Contains(data, []byte("PRIVATE KEY")) {
+		t.Errorf("Database file should not contain plaintext private keys")
+	}
+	if loginErr != nil || signErr != nil {
+		t.Fatalf("Login and NewMessage errors should be nil, got %v and %v", loginErr, signErr)
+	}
+	if _, ok := user.Key.(*keystore.SealedKey); !ok {
+		t.Errorf("Expected the loaded key to stay sealed, got %T", user.Key)
+	}
+	directory := services.NewKeyDirectoryService(withoutKeyring, nil)
+	if err := services.CheckPublishedMessage(services.NewMessageService(), directory, message); err != nil {
+		t.Errorf("Check error without the master key should be nil, got %s", err.Error())
+	}
+	if noKeyringErr == nil {
+		t.Errorf("NewMessage error without the master key should not be nil")
+	}
+}
+
+func TestMasterKeyRotation(t *testing.T) {
+	//Arrange
+	keyring := newKeyring(t, "m1")
+	db := database.NewDatabase()
+	keys := services.NewSealingKeyGenerator(defaultTestKeys(t), keyring)
+	services.NewUserServiceWithKeys(db, keys).Register("darkcat", "villv013")
+
+	//Act
+	keyring.Rotate("m2", newMasterKey(t))
+	count, err := services.RewrapKeys(db, keyring)
+	keyring.Remove("m1")
+	user, _ := db.Get("darkcat")
+	_, signErr := services.NewMessageService().NewMessage(user, "Very important message")
+
+	//Assert
+	if err != nil {
+		t.Fatalf("RewrapKeys error should be nil, got %s", err.Error())
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 rewritten user, got %d", count)
+	}
+	sealed, ok := user.Key.(*keystore.SealedKey)
+	if !ok || sealed.MasterKeyID != "m2" {
+		t.Fatalf("Expected the key to be wrapped with m2")
+	}
+	if signErr != nil {
+		t.Errorf("NewMessage error after rotation should be nil, got %s", signErr.Error())
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	//Arrange
+	first := base64.StdEncoding.EncodeToString(newMasterKey(t))
+	second := base64.StdEncoding.EncodeToString(newMasterKey(t))
+
+	//Act
+	keyring, err := keystore.ParseKeyring("m2:" + first + ", m1:" + second + "\n")
+	_, shortErr := keystore.ParseKeyring("m1:" + base64.StdEncoding.EncodeToString([]byte("short")))
+	_, formatErr := keystore.ParseKeyring(first)
+
+	//Assert
+	if err != nil {
+		t.Fatalf("ParseKeyring error should be nil, got %s", err.Error())
+	}
+	if keyring.Current() != "m2" {
+		t.Errorf("Expected current master key 'm2', got '%s'", keyring.Current())
+	}
+	if shortErr == nil || formatErr == nil {
+		t.Errorf("ParseKeyring errors for invalid keys should not be nil")
+	}
+}
+
+func defaultTestKeys(t *testing.T) *services.KeyGenerator {
+	t.Helper()
+	return &services.KeyGenerator{Algorithm: domain.KeyECDSAP256}
+}
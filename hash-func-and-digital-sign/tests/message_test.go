@@ -16,10 +16,10 @@ func TestValidMessageCheck(t *testing.T) {
 	user, _ := userService.Login("darkcat", "villv013")
 
 	messageService := services.NewMessageService()
-	hashedMessage, signature, _ := messageService.NewMessage(user, "Very important message")
+	message, _ := messageService.NewMessage(user, "Very important message")
 
 	//Act
-	err := messageService.CheckMessage(&user.Key.PublicKey, hashedMessage, signature)
+	err := messageService.CheckMessage(user.Key.Public(), message)
 
 	//Assert
 	if err != nil {
@@ -39,10 +39,10 @@ func TestInvalidMessageCheck(t *testing.T) {
 	user1, _ := userService.Login("darkcat1", "villv01333")
 
 	messageService := services.NewMessageService()
-	hashedMessage, signature, _ := messageService.NewMessage(user, "Very important message")
+	message, _ := messageService.NewMessage(user, "Very important message")
 
 	//Act
-	err := messageService.CheckMessage(&user1.Key.PublicKey, hashedMessage, signature)
+	err := messageService.CheckMessage(user1.Key.Public(), message)
 
 	//Assert
 	if err == nil {
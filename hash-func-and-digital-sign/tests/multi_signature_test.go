@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+func newCoSigners(t *testing.T, usernames ...string) (interfaces.IMultiSignatureService, map[string]domain.User) {
+	t.Helper()
+	db := database.NewDatabase()
+	userService := services.NewUserService(db)
+
+	users := make(map[string]domain.User)
+	for _, username := range usernames {
+		userService.Register(username, "villv013")
+		user, err := userService.Login(username, "villv013")
+		if err != nil {
+			t.Fatal(err)
+		}
+		users[username] = user
+	}
+	return services.NewMultiSignatureService(services.NewKeyDirectoryService(db, nil)), users
+}
+
+func TestMultiSignatureTwoOfThree(t *testing.T) {
+	//Arrange
+	multiSignatureService, users := newCoSigners(t, "admin1", "admin2", "admin3")
+	policy := domain.SignaturePolicy{Signers: []string{"admin1", "admin2", "admin3"}, Threshold: 2}
+	document, err := multiSignatureService.NewDocument("delete user darkcat", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Act
+	once, _ := multiSignatureService.CoSign(users["admin1"], document)
+	onceErr := multiSignatureService.CheckDocument(once)
+	twice, _ := multiSignatureService.CoSign(users["admin3"], once)
+	twiceErr := multiSignatureService.CheckDocument(twice)
+
+	//Assert
+	if onceErr == nil {
+		t.Errorf("CheckDocument error with one of two signatures should not be nil")
+	}
+	if missing := once.Missing(); len(missing) != 2 || missing[0] != "admin2" || missing[1] != "admin3" {
+		t.Errorf("Expected missing signers [admin2 admin3], got %v", missing)
+	}
+	if twiceErr != nil {
+		t.Errorf("CheckDocument error with two of two signatures should be nil, got %s", twiceErr.Error())
+	}
+	if missing := twice.Missing(); len(missing) != 1 || missing[0] != "admin2" {
+		t.Errorf("Expected missing signers [admin2], got %v", missing)
+	}
+	if len(document.Signatures) != 0 {
+		t.Errorf("CoSign should not modify the original document")
+	}
+}
+
+func TestMultiSignatureRejected(t *testing.T) {
+	//Arrange
+	multiSignatureService, users := newCoSigners(t, "admin1", "admin2", "darkcat")
+	policy := domain.SignaturePolicy{Signers: []string{"admin1", "admin2"}, Threshold: 2}
+	document, _ := multiSignatureService.NewDocument("delete user darkcat", policy)
+	signed, _ := multiSignatureService.CoSign(users["admin1"], document)
+	signed, _ = multiSignatureService.CoSign(users["admin2"], signed)
+
+	altered := signed
+	altered.Payload = []byte("delete user admin1")
+	lowered := signed
+	lowered.Policy = domain.SignaturePolicy{Signers: []string{"admin1", "admin2"}, Threshold: 1}
+	duplicated := signed
+	duplicated.Signatures = []domain.CoSignature{signed.Signatures[0], signed.Signatures[0]}
+
+	//Act
+	_, outsiderErr := multiSignatureService.CoSign(users["darkcat"], document)
+	_, againErr := multiSignatureService.CoSign(users["admin1"], signed)
+	_, policyErr := multiSignatureService.NewDocument("delete user darkcat", domain.SignaturePolicy{Signers: []string{"admin1"}, Threshold: 2})
+
+	//Assert
+	if err := multiSignatureService.CheckDocument(signed); err != nil {
+		t.Fatalf("CheckDocument error should be nil, got %s", err.Error())
+	}
+	if multiSignatureService.CheckDocument(altered) == nil {
+		t.Errorf("CheckDocument error for an altered payload should not be nil")
+	}
+	if multiSignatureService.CheckDocument(lowered) == nil {
+		t.Errorf("CheckDocument error for an altered policy should not be nil")
+	}
+	if multiSignatureService.CheckDocument(duplicated) == nil {
+		t.Errorf("CheckDocument error for a duplicated signature should not be nil")
+	}
+	if outsiderErr == nil {
+		t.Errorf("CoSign error for a signer not in the policy should not be nil")
+	}
+	if againErr == nil {
+		t.Errorf("CoSign error for a second signature of the same signer should not be nil")
+	}
+	if policyErr == nil {
+		t.Errorf("NewDocument error for an unsatisfiable policy should not be nil")
+	}
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/passwords"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+)
+
+// fastHasher keeps the work factors low so the tests stay quick.
+func fastHasher(algorithm passwords.Algorithm) *passwords.Hasher {
+	hasher := passwords.NewHasher()
+	hasher.Algorithm = algorithm
+	hasher.Scrypt.LogN = 10
+	hasher.BcryptCost = 4
+	return hasher
+}
+
+func TestPasswordHashVerify(t *testing.T) {
+	for _, algorithm := range []passwords.Algorithm{passwords.Argon2id, passwords.Scrypt, passwords.Bcrypt} {
+		//Arrange
+		hasher := fastHasher(algorithm)
+		encoded, err := hasher.Hash("villv013")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		//Act
+		needsRehash, err := hasher.Verify("villv013", encoded)
+		_, wrongErr := hasher.Verify("darkcat", encoded)
+
+		//Assert
+		if err != nil {
+			t.Errorf("%s: Verify error should be nil, got %s", algorithm, err.Error())
+		}
+		if needsRehash {
+			t.Errorf("%s: hash with current parameters should not need a rehash", algorithm)
+		}
+		if !errors.Is(wrongErr, passwords.ErrMismatch) {
+			t.Errorf("%s: Expected ErrMismatch for wrong password, got %v", algorithm, wrongErr)
+		}
+	}
+}
+
+func TestPasswordHashIsSaltedPHC(t *testing.T) {
+	//Arrange
+	hasher := passwords.NewHasher()
+
+	//Act
+	first, _ := hasher.Hash("villv013")
+	second, _ := hasher.Hash("villv013")
+
+	//Assert
+	if !strings.HasPrefix(first, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Errorf("Expected argon2id PHC string, got '%s'", first)
+	}
+	if first == second {
+		t.Errorf("Expected two hashes of the same password to differ")
+	}
+}
+
+func TestPasswordVerifyNeedsRehash(t *testing.T) {
+	//Arrange
+	old := fastHasher(passwords.Bcrypt)
+	encoded, _ := old.Hash("villv013")
+	current := fastHasher(passwords.Argon2id)
+	legacy := sha256.Sum256([]byte("villv013"))
+
+	//Act
+	needsRehash, err := current.Verify("villv013", encoded)
+	legacyNeedsRehash, legacyErr := current.Verify("villv013", string(legacy[:]))
+
+	//Assert
+	if err != nil || !needsRehash {
+		t.Errorf("Expected bcrypt hash to verify and need a rehash, got %v, %v", needsRehash, err)
+	}
+	if legacyErr != nil || !legacyNeedsRehash {
+		t.Errorf("Expected legacy digest to verify and need a rehash, got %v, %v", legacyNeedsRehash, legacyErr)
+	}
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	legacy := sha256.Sum256([]byte("villv013"))
+	db.Set("darkcat", domain.User{Username: "darkcat", Password: legacy[:]})
+	userService := services.NewUserServiceWithHasher(db, fastHasher(passwords.Argon2id))
+
+	//Act
+	_, err := userService.Login("darkcat", "villv013")
+
+	//Assert
+	if err != nil {
+		t.Fatalf("Login error should be nil, got %s", err.Error())
+	}
+	user, _ := db.Get("darkcat")
+	if !strings.HasPrefix(string(user.Password), "$argon2id$") {
+		t.Errorf("Expected password to be rehashed with argon2id, got '%s'", user.Password)
+	}
+}
+
+func TestPasswordVerifyRejectsBadParameters(t *testing.T) {
+	//Arrange
+	hasher := fastHasher(passwords.Argon2id)
+	encoded, _ := hasher.Hash("villv013")
+	tail := encoded[strings.LastIndex(encoded[:strings.LastIndex(encoded, "$")], "$"):]
+	malformed := []string{
+		"$argon2id$v=19$m=19456,t=0,p=1" + tail,
+		"$argon2id$v=19$m=19456,t=2,p=0" + tail,
+		"$argon2id$v=19$m=4294967295,t=2,p=1" + tail,
+		"$argon2id$v=19$m=19456,t=4294967295,p=1" + tail,
+		"$scrypt$ln=40,r=8,p=1" + tail,
+		"$scrypt$ln=10,r=8,p=0" + tail,
+		"$scrypt$ln=10,r=0,p=1" + tail,
+		"$scrypt$ln=10,r=1000,p=1" + tail,
+		"$scrypt$ln=10,r=8,p=1000" + tail,
+	}
+
+	for _, stored := range malformed {
+		//Act
+		_, err := hasher.Verify("villv013", stored)
+
+		//Assert
+		if !errors.Is(err, passwords.ErrInvalidFormat) {
+			t.Errorf("%s: Expected ErrInvalidFormat, got %v", stored, err)
+		}
+	}
+}
+
+func TestLoginWithMalformedStoredHash(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	db.Set("darkcat", domain.User{Username: "darkcat", Password: []byte("$argon2id$v=19$m=19456,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5")})
+	userService := services.NewUserServiceWithHasher(db, fastHasher(passwords.Argon2id))
+
+	//Act
+	_, err := userService.Login("darkcat", "villv013")
+
+	//Assert
+	if err == nil {
+		t.Fatalf("Login error with a malformed stored hash should not be nil")
+	}
+}
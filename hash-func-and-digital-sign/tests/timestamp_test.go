@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"crypto"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/database"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/interfaces"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/services"
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/utils"
+)
+
+func newTimestampAuthority(t *testing.T) interfaces.ITimestampAuthority {
+	t.Helper()
+	authority, err := services.NewTimestampAuthority("cs-labs tsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authority
+}
+
+func TestTimestampedMessage(t *testing.T) {
+	//Arrange
+	user, _ := newSignedMessage(t)
+	messageService := services.NewTimestampedMessageService(newTimestampAuthority(t))
+
+	//Act
+	message, err := messageService.NewMessage(user, "Very important message")
+
+	//Assert
+	if err != nil {
+		t.Fatalf("NewMessage error should be nil, got %s", err.Error())
+	}
+	if message.TimestampToken == nil {
+		t.Fatalf("Expected message to carry a timestamp token")
+	}
+	if err := messageService.CheckMessage(user.Key.Public(), message); err != nil {
+		t.Errorf("CheckMessage error should be nil, got %s", err.Error())
+	}
+
+	data, _ := message.MarshalBinary()
+	var decoded domain.SignedMessage
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary error should be nil, got %s", err.Error())
+	}
+	if err := messageService.CheckMessage(user.Key.Public(), decoded); err != nil {
+		t.Errorf("CheckMessage error after binary round trip should be nil, got %s", err.Error())
+	}
+
+	data, _ = json.Marshal(message)
+	decoded = domain.SignedMessage{}
+	json.Unmarshal(data, &decoded)
+	if err := messageService.CheckMessage(user.Key.Public(), decoded); err != nil {
+		t.Errorf("CheckMessage error after JSON round trip should be nil, got %s", err.Error())
+	}
+}
+
+func TestTimestampTokenRejected(t *testing.T) {
+	//Arrange
+	user, untimestamped := newSignedMessage(t)
+	authority := newTimestampAuthority(t)
+	messageService := services.NewTimestampedMessageService(authority)
+	rogueService := services.NewTimestampedMessageService(newTimestampAuthority(t))
+
+	message, _ := messageService.NewMessage(user, "Very important message")
+	rogue, _ := rogueService.NewMessage(user, "Very important message")
+
+	backdated := message
+	token := *message.TimestampToken
+	token.Time = token.Time.Add(-time.Hour)
+	backdated.TimestampToken = &token
+
+	//Act
+	untimestampedErr := messageService.CheckMessage(user.Key.Public(), untimestamped)
+	rogueErr := messageService.CheckMessage(user.Key.Public(), rogue)
+	backdatedErr := messageService.CheckMessage(user.Key.Public(), backdated)
+
+	//Assert
+	if untimestampedErr == nil {
+		t.Errorf("CheckMessage error for a message without token should not be nil")
+	}
+	if rogueErr == nil {
+		t.Errorf("CheckMessage error for a token of another authority should not be nil")
+	}
+	if backdatedErr == nil {
+		t.Errorf("CheckMessage error for a modified token should not be nil")
+	}
+}
+
+func TestTimestampProvesSigningBeforeRevocation(t *testing.T) {
+	//Arrange
+	db := database.NewDatabase()
+	userService := services.NewUserService(db)
+	userService.Register("darkcat", "villv013")
+	user, _ := userService.Login("darkcat", "villv013")
+	authority := newTimestampAuthority(t)
+	messageService := services.NewTimestampedMessageService(authority)
+	directory := services.NewKeyDirectoryService(db, nil)
+
+	honest, _ := messageService.NewMessage(user, "Very important message")
+	time.Sleep(time.Millisecond)
+	services.NewKeyService(db).Revoke("darkcat", honest.KeyID, domain.ReasonKeyCompromise)
+
+	// the holder of the compromised key signs a message claiming an old time
+	forged, _ := services.NewMessageService().NewMessage(user, "Forged message")
+	forged.Timestamp = honest.Timestamp
+	digest, _ := utils.GetDigest(forged.HashAlgorithm, forged.SigningInput())
+	forged.Signature, _ = utils.SignDigest(user.Key, forged.SignatureScheme, crypto.SHA256, digest)
+	token, _ := authority.Timestamp(domain.HashSHA256, mustDigest(t, forged.Signature))
+	forged.TimestampToken = &token
+
+	//Act
+	honestErr := services.CheckTimestampedMessage(messageService, directory, authority, honest)
+	claimedErr := services.CheckPublishedMessage(services.NewMessageService(), directory, forged)
+	forgedErr := services.CheckTimestampedMessage(messageService, directory, authority, forged)
+
+	//Assert
+	if honestErr != nil {
+		t.Errorf("Check error for message timestamped before revocation should be nil, got %s", honestErr.Error())
+	}
+	if claimedErr != nil {
+		t.Errorf("Backdated message should pass the check on its claimed time, got %s", claimedErr.Error())
+	}
+	if forgedErr == nil {
+		t.Errorf("Check error for message timestamped after revocation should not be nil")
+	}
+}
+
+func mustDigest(t *testing.T, data []byte) []byte {
+	t.Helper()
+	digest, err := utils.GetDigest(domain.HashSHA256, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return digest
+}
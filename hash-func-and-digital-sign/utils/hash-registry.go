@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"crypto"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+
+	// register the SHA-3 and BLAKE2 implementations with crypto.Hash
+	_ "golang.org/x/crypto/blake2b"
+	_ "golang.org/x/crypto/blake2s"
+	_ "golang.org/x/crypto/sha3"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+)
+
+var hashAlgorithms = map[string]crypto.Hash{
+	domain.HashSHA224:     crypto.SHA224,
+	domain.HashSHA256:     crypto.SHA256,
+	domain.HashSHA384:     crypto.SHA384,
+	domain.HashSHA512:     crypto.SHA512,
+	domain.HashSHA3_256:   crypto.SHA3_256,
+	domain.HashSHA3_512:   crypto.SHA3_512,
+	domain.HashBLAKE2b256: crypto.BLAKE2b_256,
+	domain.HashBLAKE2b512: crypto.BLAKE2b_512,
+	domain.HashBLAKE2s256: crypto.BLAKE2s_256,
+}
+
+// LookupHash returns the hash function registered under name.
+func LookupHash(name string) (crypto.Hash, error) {
+	if h, ok := hashAlgorithms[name]; ok && h.Available() {
+		return h, nil
+	}
+	return 0, fmt.Errorf("hash registry | unsupported hash algorithm %q", name)
+}
+
+// NewHash returns a streaming hash.Hash for name.
+func NewHash(name string) (hash.Hash, error) {
+	h, err := LookupHash(name)
+	if err != nil {
+		return nil, err
+	}
+	return h.New(), nil
+}
+
+func HashNames() []string {
+	names := make([]string, 0, len(hashAlgorithms))
+	for name := range hashAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func GetDigest(name string, input []byte) ([]byte, error) {
+	hash, err := NewHash(name)
+	if err != nil {
+		return nil, err
+	}
+	hash.Write(input)
+	return hash.Sum(nil), nil
+}
+
+// GetStreamDigest hashes everything read from r and returns the digest and
+// the number of bytes read.
+func GetStreamDigest(name string, r io.Reader) ([]byte, int64, error) {
+	hash, err := NewHash(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hash.Sum(nil), size, nil
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+)
+
+// GetKeyID returns a short fingerprint of the public key: the first 8 bytes
+// of the SHA-256 digest of its PKIX encoding, in hex.
+func GetKeyID(publicKey crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		panic(err)
+	}
+	digest := sha256.Sum256(der)
+	return hex.EncodeToString(digest[:8])
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+
+	"github.com/darkcat013/cs-labs/asymmetric-ciphers/shamir"
+)
+
+// SplitSigningKey splits the PKCS#8 encoding of key into n shares,
+// any k of which restore it with CombineSigningKey.
+func SplitSigningKey(key crypto.Signer, n, k int) ([]shamir.Share, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return shamir.Split(der, n, k)
+}
+
+// CombineSigningKey restores a key from at least k of the shares made by
+// SplitSigningKey.
+func CombineSigningKey(shares []shamir.Share) (crypto.Signer, error) {
+	der, err := shamir.Combine(shares)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("key shares | key cannot sign")
+	}
+	return signer, nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/darkcat013/cs-labs/hash-func-and-digital-sign/domain"
+)
+
+// PKCS#1 v1.5 needs a DigestInfo prefix per hash, which only the SHA-2
+// family has everywhere, so the other hashes are signed with PSS.
+var pkcs1v15Hashes = map[crypto.Hash]bool{
+	crypto.SHA224: true,
+	crypto.SHA256: true,
+	crypto.SHA384: true,
+	crypto.SHA512: true,
+}
+
+// the salt takes whatever room the key leaves, so 512 bit hashes fit small keys too
+var pssOptions = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}
+
+// DefaultScheme returns the signature scheme used for new signatures with
+// publicKey and hash.
+func DefaultScheme(publicKey crypto.PublicKey, hash crypto.Hash) string {
+	if _, ok := publicKey.(*ecdsa.PublicKey); ok {
+		return domain.SchemeECDSA
+	}
+	if pkcs1v15Hashes[hash] {
+		return domain.SchemePKCS1v15
+	}
+	return domain.SchemePSS
+}
+
+func SignDigest(key crypto.Signer, scheme string, hash crypto.Hash, digest []byte) ([]byte, error) {
+	switch key.Public().(type) {
+	case *rsa.PublicKey:
+		switch {
+		case scheme == domain.SchemePKCS1v15 && pkcs1v15Hashes[hash]:
+			return key.Sign(rand.Reader, digest, hash)
+		case scheme == domain.SchemePSS:
+			return key.Sign(rand.Reader, digest, &rsa.PSSOptions{SaltLength: pssOptions.SaltLength, Hash: hash})
+		}
+	case *ecdsa.PublicKey:
+		if scheme == domain.SchemeECDSA {
+			return key.Sign(rand.Reader, digest, hash)
+		}
+	}
+	return nil, fmt.Errorf("signature schemes | unsupported scheme %q with %v for %T", scheme, hash, key.Public())
+}
+
+func VerifyDigest(publicKey crypto.PublicKey, scheme string, hash crypto.Hash, digest, signature []byte) error {
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		switch {
+		case scheme == domain.SchemePKCS1v15 && pkcs1v15Hashes[hash]:
+			return rsa.VerifyPKCS1v15(publicKey, hash, digest, signature)
+		case scheme == domain.SchemePSS:
+			return rsa.VerifyPSS(publicKey, hash, digest, signature, pssOptions)
+		}
+	case *ecdsa.PublicKey:
+		if scheme == domain.SchemeECDSA {
+			if !ecdsa.VerifyASN1(publicKey, digest, signature) {
+				return fmt.Errorf("signature schemes | ECDSA verification error")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("signature schemes | unsupported scheme %q with %v for %T", scheme, hash, publicKey)
+}